@@ -0,0 +1,160 @@
+package plum
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessLogFormat is the format of the records written by AccessLog.
+type AccessLogFormat int
+
+const (
+	// AccessLogStructured logs one message with the request fields as attributes,
+	// rendered as JSON by a slog.JSONHandler.
+	AccessLogStructured AccessLogFormat = iota
+	// AccessLogCommon logs the Apache Common Log Format line as message.
+	AccessLogCommon
+	// AccessLogCombined logs the Apache Combined Log Format line as message.
+	AccessLogCombined
+)
+
+// commonLogTime is the time layout of the Apache log formats.
+const commonLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogConfig configures the middleware returned by AccessLog.
+type AccessLogConfig struct {
+	Format AccessLogFormat
+	// SkipPaths are the request paths not logged, such as health checks.
+	SkipPaths []string
+	// SampleRate is the fraction of the requests logged, all of them if zero.
+	// The server errors and the slow requests are always logged.
+	SampleRate float64
+	// SlowThreshold raises the level of the requests slower than it to warning, if not zero.
+	SlowThreshold time.Duration
+}
+
+// AccessLog returns a middleware that writes one record per request through the
+// engine's Logger, at error level for the server errors, warning level for the
+// slow requests and info level otherwise.
+func AccessLog(cfg AccessLogConfig) Middleware {
+	skip := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			if _, ok := skip[ctx.Request.URL.Path]; ok {
+				handler(ctx)
+				return
+			}
+
+			r := accessRecord{start: time.Now()}
+			// The request may be rewritten by the handlers.
+			r.method, r.path, r.proto = ctx.Request.Method, ctx.Request.URL.RequestURI(), ctx.Request.Proto
+
+			// The record is written by a deferred call so that the panicking requests
+			// are logged, with the 500 written by the Recover middleware, before
+			// the panic is propagated to it.
+			defer func() {
+				rec := recover()
+				r.status = ctx.Writer.Status()
+				if rec != nil && !ctx.Writer.Written() {
+					r.status = http.StatusInternalServerError
+				}
+				r.log(ctx, cfg)
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+			handler(ctx)
+		}
+	}
+}
+
+// accessRecord is the request logged by AccessLog.
+type accessRecord struct {
+	start               time.Time
+	method, path, proto string
+	status              int
+}
+
+func (r accessRecord) log(ctx *Context, cfg AccessLogConfig) {
+	latency := time.Since(r.start)
+	slow := cfg.SlowThreshold > 0 && latency >= cfg.SlowThreshold
+	if cfg.SampleRate > 0 && r.status < http.StatusInternalServerError && !slow &&
+		rand.Float64() >= cfg.SampleRate {
+		return
+	}
+
+	log := ctx.engine.opts.Log.Info
+	switch {
+	case r.status >= http.StatusInternalServerError:
+		log = ctx.engine.opts.Log.Error
+	case slow:
+		log = ctx.engine.opts.Log.Warn
+	}
+
+	size := ctx.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+	switch cfg.Format {
+	case AccessLogCommon, AccessLogCombined:
+		var b strings.Builder
+		b.WriteString(ctx.ClientIP())
+		b.WriteString(" - ")
+		b.WriteString(orDash(basicAuthUser(ctx.Request)))
+		b.WriteString(" [")
+		b.WriteString(r.start.Format(commonLogTime))
+		b.WriteString(`] "`)
+		b.WriteString(r.method + " " + r.path + " " + r.proto)
+		b.WriteString(`" `)
+		b.WriteString(strconv.Itoa(r.status))
+		b.WriteByte(' ')
+		if size == 0 {
+			b.WriteByte('-')
+		} else {
+			b.WriteString(strconv.Itoa(size))
+		}
+		if cfg.Format == AccessLogCombined {
+			b.WriteString(` "` + orDash(ctx.Request.Referer()) + `" "` + orDash(ctx.Request.UserAgent()) + `"`)
+		}
+		log(b.String())
+	default:
+		log("plum: request",
+			"method", r.method,
+			"route", ctx.FullPath(),
+			"path", r.path,
+			"status", r.status,
+			"bytes", size,
+			"latency", latency,
+			"client_ip", ctx.ClientIP(),
+			"user_agent", ctx.Request.UserAgent(),
+			"request_id", requestID(ctx),
+		)
+	}
+}
+
+// requestID returns the request id of the request, or the one set on the response.
+func requestID(ctx *Context) string {
+	if id := ctx.requestHeader(HeaderXRequestID); id != "" {
+		return id
+	}
+	return ctx.Writer.Header().Get(HeaderXRequestID)
+}
+
+func basicAuthUser(req *http.Request) string {
+	user, _, _ := req.BasicAuth()
+	return user
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+package plum
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAccessLogEngine returns an engine logging as JSON lines into buf, with AccessLog.
+func newAccessLogEngine(buf *bytes.Buffer, cfg AccessLogConfig) *Plum {
+	p := New(WithLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+	p.Use(AccessLog(cfg))
+	return p
+}
+
+// accessRecords returns the records of buf written by AccessLog.
+func accessRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if msg, _ := record["msg"].(string); msg == "plum: request" || strings.Contains(msg, `HTTP/1.1" `) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestAccessLogStructured(t *testing.T) {
+	var buf bytes.Buffer
+	p := newAccessLogEngine(&buf, AccessLogConfig{})
+	p.GET("/users/{id}", func(c *Context) {
+		c.String(http.StatusCreated, "hello")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?q=a", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set(HeaderXRequestID, "abc")
+	p.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := accessRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("%d records, want 1: %s", len(records), buf.String())
+	}
+	want := map[string]any{
+		"level":      "INFO",
+		"method":     "GET",
+		"route":      "/users/{id}",
+		"path":       "/users/1?q=a",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(5),
+		"client_ip":  "192.0.2.1",
+		"user_agent": "test",
+		"request_id": "abc",
+	}
+	for k, v := range want {
+		if records[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, records[0][k], v)
+		}
+	}
+}
+
+func TestAccessLogApacheFormats(t *testing.T) {
+	tests := []struct {
+		format AccessLogFormat
+		want   string
+	}{
+		{AccessLogCommon, `^192\.0\.2\.1 - alice \[[^]]+\] "GET /a\?b=c HTTP/1\.1" 200 2$`},
+		{AccessLogCombined, `^192\.0\.2\.1 - alice \[[^]]+\] "GET /a\?b=c HTTP/1\.1" 200 2 "http://example\.com/" "test"$`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		p := newAccessLogEngine(&buf, AccessLogConfig{Format: tt.format})
+		p.GET("/a", func(c *Context) {
+			c.String(http.StatusOK, "ok")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/a?b=c", nil)
+		req.SetBasicAuth("alice", "secret")
+		req.Header.Set("Referer", "http://example.com/")
+		req.Header.Set("User-Agent", "test")
+		p.ServeHTTP(httptest.NewRecorder(), req)
+
+		records := accessRecords(t, &buf)
+		if len(records) != 1 {
+			t.Fatalf("%d records, want 1: %s", len(records), buf.String())
+		}
+		if msg := records[0]["msg"].(string); !regexp.MustCompile(tt.want).MatchString(msg) {
+			t.Errorf("format %d: message %q, want it to match %s", tt.format, msg, tt.want)
+		}
+	}
+}
+
+func TestAccessLogSkipPaths(t *testing.T) {
+	var buf bytes.Buffer
+	p := newAccessLogEngine(&buf, AccessLogConfig{SkipPaths: []string{"/health"}})
+	p.GET("/health", func(c *Context) {})
+	p.GET("/a", func(c *Context) {})
+
+	serve(p, http.MethodGet, "/health")
+	serve(p, http.MethodGet, "/a")
+
+	records := accessRecords(t, &buf)
+	if len(records) != 1 || records[0]["path"] != "/a" {
+		t.Errorf("records = %v, want only /a", records)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	p := newAccessLogEngine(&buf, AccessLogConfig{SampleRate: 1e-12})
+	p.GET("/ok", func(c *Context) {})
+	p.GET("/fail", func(c *Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	for i := 0; i < 10; i++ {
+		serve(p, http.MethodGet, "/ok")
+	}
+	serve(p, http.MethodGet, "/fail")
+
+	records := accessRecords(t, &buf)
+	if len(records) != 1 || records[0]["path"] != "/fail" || records[0]["level"] != "ERROR" {
+		t.Errorf("records = %v, want only the server error", records)
+	}
+}
+
+func TestAccessLogSlowThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	p := newAccessLogEngine(&buf, AccessLogConfig{SampleRate: 1e-12, SlowThreshold: time.Millisecond})
+	p.GET("/slow", func(c *Context) {
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	serve(p, http.MethodGet, "/slow")
+
+	records := accessRecords(t, &buf)
+	if len(records) != 1 || records[0]["level"] != "WARN" {
+		t.Errorf("records = %v, want a warning for the slow request", records)
+	}
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	var buf bytes.Buffer
+	p := newAccessLogEngine(&buf, AccessLogConfig{})
+	p.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	w := serve(p, http.MethodGet, "/panic")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	records := accessRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("%d records, want 1: %s", len(records), buf.String())
+	}
+	if records[0]["status"] != float64(http.StatusInternalServerError) || records[0]["level"] != "ERROR" {
+		t.Errorf("record = %v, want a 500 at error level", records[0])
+	}
+	if !strings.Contains(buf.String(), "boom") {
+		t.Errorf("the panic is not logged by Recover: %s", buf.String())
+	}
+}
@@ -0,0 +1,61 @@
+package plum
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// AllowIPs returns a middleware that rejects with 403 the requests whose client IP
+// is not in one of the given addresses or CIDR prefixes. It panics if one of them is invalid.
+func AllowIPs(cidrs ...string) Middleware {
+	prefixes := mustParsePrefixes(cidrs)
+
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ip, err := netip.ParseAddr(ctx.ClientIP())
+			if err == nil && prefixesContain(prefixes, ip) {
+				handler(ctx)
+				return
+			}
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+}
+
+// BasicAuth returns a middleware that requires HTTP basic authentication
+// with one of the given user/password pairs.
+func BasicAuth(accounts map[string]string) Middleware {
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			user, password, ok := ctx.Request.BasicAuth()
+			if ok {
+				expected, found := accounts[user]
+				if found && subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1 {
+					handler(ctx)
+					return
+				}
+			}
+			ctx.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+}
+
+// BearerToken returns a middleware that requires the given token
+// in an "Authorization: Bearer" header.
+func BearerToken(token string) Middleware {
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			auth := ctx.requestHeader("Authorization")
+			if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") &&
+				subtle.ConstantTimeCompare([]byte(auth[7:]), []byte(token)) == 1 {
+				handler(ctx)
+				return
+			}
+			ctx.Header("WWW-Authenticate", "Bearer")
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package binding
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 // Content-Type MIME of the most common data formats.
 const (
@@ -34,3 +37,42 @@ type BindingUri interface {
 	Name() string
 	BindUri(map[string][]string, any) error
 }
+
+// These implement the Binding interface and can be used to bind the data
+// present in the request to struct instances. QueryTagged only binds the query
+// to the fields with a `form` tag, unlike Query which also binds the untagged
+// fields by their names.
+var (
+	JSON          BindingBody = jsonBinding{}
+	XML           BindingBody = xmlBinding{}
+	Form          Binding     = formBinding{}
+	Query         Binding     = queryBinding{}
+	QueryTagged   Binding     = queryBinding{tagged: true}
+	FormPost      Binding     = formPostBinding{}
+	FormMultipart Binding     = formMultipartBinding{}
+	Header        Binding     = headerBinding{}
+	Uri           BindingUri  = uriBinding{}
+)
+
+// Default returns the appropriate Binding instance based on the HTTP method
+// and the content type.
+func Default(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+
+	switch contentType {
+	case MIMEJSON:
+		return JSON
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEMultipartPOSTForm:
+		return FormMultipart
+	case MIMEPOSTForm:
+		return Form
+	}
+	if strings.HasSuffix(contentType, "+json") {
+		return JSON
+	}
+	return Form
+}
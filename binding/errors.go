@@ -0,0 +1,66 @@
+package binding
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Sources of the values bound to the fields.
+const (
+	SourceBody   = "body"
+	SourceQuery  = "query"
+	SourceURI    = "uri"
+	SourceHeader = "header"
+)
+
+// FieldError describes a value that cannot be bound to a field.
+type FieldError struct {
+	// Field is the path of the field, the key of the value for forms,
+	// queries, headers and uris, the JSON path for JSON bodies.
+	Field string `json:"name"`
+	// Source is where the value comes from: SourceBody, SourceQuery, SourceURI or SourceHeader.
+	Source string `json:"source"`
+	// Expected is the Go type of the field.
+	Expected string `json:"expected,omitempty"`
+	Reason   string `json:"reason"`
+	Err      error  `json:"-"`
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	msg := "binding: " + e.Source + " " + strconv.Quote(e.Field) + ": " + e.Reason
+	if e.Expected != "" {
+		msg += " (expected " + e.Expected + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is the error returned by the bindings when values cannot be bound.
+type FieldErrors []*FieldError
+
+// Error implements the error interface.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// reason returns the message of err without the details repeated by FieldError.
+func reason(err error) string {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return strconv.Quote(ne.Num) + ": " + ne.Err.Error()
+	}
+	if errors.Is(err, errUnknownType) {
+		return "unsupported field type"
+	}
+	return err.Error()
+}
@@ -0,0 +1,53 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+)
+
+const defaultMemory = 32 << 20
+
+type formBinding struct{}
+type formPostBinding struct{}
+type formMultipartBinding struct{}
+
+func (formBinding) Name() string {
+	return "form"
+}
+
+// Bind (form) binds the query and the body of url-encoded or multipart forms.
+func (formBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	src := requestFormSource{formSource: formSource(req.Form), post: req.PostForm}
+	return mapFormSource(obj, req.Form, src, SourceQuery)
+}
+
+func (formPostBinding) Name() string {
+	return "form-urlencoded"
+}
+
+// Bind (form-urlencoded) binds the body of url-encoded forms only.
+func (formPostBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	return mapForm(obj, req.PostForm, SourceBody)
+}
+
+func (formMultipartBinding) Name() string {
+	return "multipart/form-data"
+}
+
+// Bind (multipart/form-data) binds the values and the files of multipart forms,
+// into *multipart.FileHeader and []*multipart.FileHeader fields for the latter.
+func (formMultipartBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseMultipartForm(defaultMemory); err != nil {
+		return err
+	}
+	return mapping(obj, multipartSource{req.MultipartForm}, SourceBody, "form", true)
+}
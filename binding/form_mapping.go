@@ -0,0 +1,457 @@
+package binding
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownType = errors.New("unknown type")
+
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// source looks up the values bound to a key.
+type source interface {
+	values(key string) ([]string, bool)
+}
+
+// originSource is a source whose values come from several origins.
+type originSource interface {
+	source
+	origin(key string) string
+}
+
+// fileSource is a source that also holds uploaded files.
+type fileSource interface {
+	source
+	files(key string) ([]*multipart.FileHeader, bool)
+}
+
+type formSource map[string][]string
+
+func (fs formSource) values(key string) ([]string, bool) {
+	v, ok := fs[key]
+	return v, ok
+}
+
+// requestFormSource is the query and the body of a form, the body values first.
+type requestFormSource struct {
+	formSource
+	post map[string][]string
+}
+
+func (rs requestFormSource) origin(key string) string {
+	if _, ok := rs.post[key]; ok {
+		return SourceBody
+	}
+	return SourceQuery
+}
+
+type multipartSource struct {
+	form *multipart.Form
+}
+
+func (ms multipartSource) values(key string) ([]string, bool) {
+	v, ok := ms.form.Value[key]
+	return v, ok
+}
+
+func (ms multipartSource) files(key string) ([]*multipart.FileHeader, bool) {
+	f, ok := ms.form.File[key]
+	return f, ok
+}
+
+// mapForm binds form values from origin to obj, which may also point to
+// a map[string]string or a map[string][]string.
+func mapForm(obj any, form map[string][]string, origin string) error {
+	return mapFormSource(obj, form, formSource(form), origin)
+}
+
+// mapFormSource is mapForm binding the fields of a struct to the values of src.
+func mapFormSource(obj any, form map[string][]string, src source, origin string) error {
+	switch m := obj.(type) {
+	case *map[string]string:
+		if *m == nil {
+			*m = make(map[string]string, len(form))
+		}
+		for k, v := range form {
+			(*m)[k] = v[len(v)-1]
+		}
+		return nil
+	case *map[string][]string:
+		if *m == nil {
+			*m = make(map[string][]string, len(form))
+		}
+		for k, v := range form {
+			(*m)[k] = v
+		}
+		return nil
+	}
+	return mapping(obj, src, origin, "form", true)
+}
+
+// mapping binds the values of src to the struct pointed to by obj, using the given
+// struct tag for the keys. Untagged fields are bound by their names if byName is set.
+// The values that cannot be bound are reported as FieldErrors from origin,
+// or from the origin of their key for an originSource.
+//
+// The tag value is the key followed by options, as in `form:"page,default=1"`.
+// Fields of struct type are bound with the key of the field and a dot as prefix
+// for the keys of their own fields, or without prefix if they are untagged.
+func mapping(obj any, src source, origin, tag string, byName bool) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("binding: %T is not a non-nil pointer", obj)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("binding: %T does not point to a struct", obj)
+	}
+	m := &mapper{src: src, origin: origin, tag: tag, byName: byName, mapping: make(map[reflect.Type]bool)}
+	m.mapStruct(rv, "")
+	if len(m.errs) > 0 {
+		return m.errs
+	}
+	return nil
+}
+
+type mapper struct {
+	src    source
+	origin string
+	tag    string
+	byName bool
+	errs   FieldErrors
+	// mapping are the struct types being mapped, a nested pointer to one of them
+	// is not followed so that recursive types such as `Parent *Node` terminate.
+	mapping map[reflect.Type]bool
+}
+
+// mapStruct binds the fields of v and reports whether any of them was set.
+func (m *mapper) mapStruct(v reflect.Value, prefix string) bool {
+	var isSet bool
+	t := v.Type()
+	m.mapping[t] = true
+	defer delete(m.mapping, t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		// The exported fields of an embedded struct are promoted even if its type is not.
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct && isNestedStruct(sf.Type)) {
+			continue
+		}
+		name, opts := parseTag(sf.Tag.Get(m.tag))
+		if name == "-" {
+			continue
+		}
+
+		if isNestedStruct(sf.Type) {
+			nestedPrefix := prefix
+			if name != "" {
+				nestedPrefix = prefix + name + "."
+			}
+			isSet = m.mapNested(v.Field(i), nestedPrefix) || isSet
+			continue
+		}
+
+		if name == "" {
+			if !m.byName {
+				continue
+			}
+			name = sf.Name
+		}
+		key := prefix + name
+		ok, err := m.mapField(v.Field(i), sf, key, opts)
+		if err != nil {
+			origin := m.origin
+			if os, ok := m.src.(originSource); ok {
+				origin = os.origin(key)
+			}
+			m.errs = append(m.errs, &FieldError{
+				Field:    key,
+				Source:   origin,
+				Expected: sf.Type.String(),
+				Reason:   reason(err),
+				Err:      err,
+			})
+			continue
+		}
+		isSet = isSet || ok
+	}
+	return isSet
+}
+
+// mapNested binds a field of struct or pointer to struct type, the pointer
+// is only allocated when one of the fields is set.
+func (m *mapper) mapNested(v reflect.Value, prefix string) bool {
+	if v.Kind() != reflect.Pointer {
+		return m.mapStruct(v, prefix)
+	}
+	if m.mapping[v.Type().Elem()] {
+		return false
+	}
+	elem := v
+	if v.IsNil() {
+		elem = reflect.New(v.Type().Elem())
+	}
+	ok := m.mapStruct(elem.Elem(), prefix)
+	if ok && v.IsNil() {
+		v.Set(elem)
+	}
+	return ok
+}
+
+func (m *mapper) mapField(v reflect.Value, sf reflect.StructField, key string, opts tagOptions) (bool, error) {
+	if isFileType(sf.Type) {
+		fs, ok := m.src.(fileSource)
+		if !ok {
+			return false, nil
+		}
+		files, ok := fs.files(key)
+		if !ok || len(files) == 0 {
+			return false, nil
+		}
+		return true, setFiles(v, files)
+	}
+
+	vals, ok := m.src.values(key)
+	if !ok || len(vals) == 0 {
+		def, hasDefault := opts.get("default")
+		if !hasDefault {
+			return false, nil
+		}
+		vals = []string{def}
+		if isMultiValue(v.Type()) {
+			vals = strings.Split(def, ";")
+		}
+	}
+	return true, setValues(v, vals, sf)
+}
+
+// setValues sets v from all the values for slices and arrays, from the first one otherwise.
+func setValues(v reflect.Value, vals []string, sf reflect.StructField) error {
+	if !isMultiValue(v.Type()) {
+		return setValue(v, vals[0], sf)
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(s.Index(i), val, sf); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		if len(vals) != v.Len() {
+			return fmt.Errorf("%q is not valid value for %s", vals, v.Type())
+		}
+		for i, val := range vals {
+			if err := setValue(v.Index(i), val, sf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, val string, sf reflect.StructField) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), val, sf)
+	}
+	if v.Type() == timeType {
+		return setTime(v, val, sf)
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(val))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+		return nil
+	case reflect.Bool:
+		if val == "" {
+			val = "false"
+		}
+		b, err := strconv.ParseBool(val)
+		if err == nil {
+			v.SetBool(b)
+		}
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			return setDuration(v, val)
+		}
+		if val == "" {
+			val = "0"
+		}
+		n, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err == nil {
+			v.SetInt(n)
+		}
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if val == "" {
+			val = "0"
+		}
+		n, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err == nil {
+			v.SetUint(n)
+		}
+		return err
+	case reflect.Float32, reflect.Float64:
+		if val == "" {
+			val = "0"
+		}
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err == nil {
+			v.SetFloat(f)
+		}
+		return err
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(val))
+			return nil
+		}
+	}
+	return errUnknownType
+}
+
+// setTime parses val according to the `time_format` tag, RFC 3339 by default,
+// which may also be "unix", "unixmilli", "unixmicro" or "unixnano".
+// The `time_utc` and `time_location` tags set the location of the time.
+func setTime(v reflect.Value, val string, sf reflect.StructField) error {
+	if val == "" {
+		v.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+
+	layout := sf.Tag.Get("time_format")
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	switch strings.ToLower(layout) {
+	case "unix", "unixmilli", "unixmicro", "unixnano":
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		var t time.Time
+		switch strings.ToLower(layout) {
+		case "unix":
+			t = time.Unix(n, 0)
+		case "unixmilli":
+			t = time.UnixMilli(n)
+		case "unixmicro":
+			t = time.UnixMicro(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	loc := time.Local
+	if isUTC, _ := strconv.ParseBool(sf.Tag.Get("time_utc")); isUTC {
+		loc = time.UTC
+	}
+	if locTag := sf.Tag.Get("time_location"); locTag != "" {
+		l, err := time.LoadLocation(locTag)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(layout, val, loc)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func setDuration(v reflect.Value, val string) error {
+	if val == "" {
+		val = "0"
+	}
+	d, err := time.ParseDuration(val)
+	if err == nil {
+		v.SetInt(int64(d))
+	}
+	return err
+}
+
+func setFiles(v reflect.Value, files []*multipart.FileHeader) error {
+	switch {
+	case v.Type() == fileHeaderType:
+		v.Set(reflect.ValueOf(*files[0]))
+	case v.Type() == reflect.PointerTo(fileHeaderType):
+		v.Set(reflect.ValueOf(files[0]))
+	case v.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(files), len(files))
+		for i, f := range files {
+			if err := setFiles(s.Index(i), []*multipart.FileHeader{f}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	default:
+		return errUnknownType
+	}
+	return nil
+}
+
+// isNestedStruct reports whether fields of type t are bound field by field.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != fileHeaderType &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isMultiValue reports whether fields of type t are bound from all the values of their key.
+func isMultiValue(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func isFileType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t == fileHeaderType
+}
+
+// tagOptions are the comma-separated "key=value" options following the name of a tag.
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+func (o tagOptions) get(key string) (string, bool) {
+	for s := string(o); s != ""; {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if k, v, ok := strings.Cut(opt, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package binding
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type node struct {
+	Name   string `form:"name"`
+	Parent *node  `form:"parent"`
+	Next   *node
+}
+
+func TestMappingRecursiveType(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?name=leaf&parent.name=root", nil)
+	var n node
+	if err := Query.Bind(req, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n.Name != "leaf" {
+		t.Errorf("Name = %q, want %q", n.Name, "leaf")
+	}
+	if n.Parent != nil || n.Next != nil {
+		t.Errorf("recursive fields are set: %+v", n)
+	}
+
+	req.Header.Set("Name", "leaf")
+	if err := Header.Bind(req, &n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type page struct {
+	Page int `form:"page"`
+	Size int `form:"size,default=20"`
+}
+
+type list struct {
+	page
+	Q string `form:"q"`
+}
+
+func TestMappingEmbeddedUnexportedStruct(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?page=3&q=x", nil)
+	var l list
+	if err := Query.Bind(req, &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.Page != 3 || l.Size != 20 || l.Q != "x" {
+		t.Errorf("got %+v, want Page 3, Size 20 and Q x", l)
+	}
+}
@@ -0,0 +1,29 @@
+package binding
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormFieldErrorSource(t *testing.T) {
+	var obj struct {
+		Page int `form:"page"`
+		Age  int `form:"age"`
+	}
+	req := httptest.NewRequest("POST", "/?page=x", strings.NewReader("age=y"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var fes FieldErrors
+	if err := Form.Bind(req, &obj); !errors.As(err, &fes) {
+		t.Fatalf("error %v is not a FieldErrors", err)
+	}
+	sources := map[string]string{}
+	for _, fe := range fes {
+		sources[fe.Field] = fe.Source
+	}
+	if sources["page"] != SourceQuery || sources["age"] != SourceBody {
+		t.Errorf("sources = %v, want page from query and age from body", sources)
+	}
+}
@@ -0,0 +1,24 @@
+package binding
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+// Bind (header) binds the fields tagged with `header`, whose names are canonicalized.
+func (headerBinding) Bind(req *http.Request, obj any) error {
+	return mapping(obj, headerSource(req.Header), SourceHeader, "header", false)
+}
+
+type headerSource map[string][]string
+
+func (hs headerSource) values(key string) ([]string, bool) {
+	v, ok := hs[textproto.CanonicalMIMEHeaderKey(key)]
+	return v, ok
+}
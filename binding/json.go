@@ -0,0 +1,91 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-plum/plum/codec"
+	"github.com/go-plum/plum/internal/json"
+)
+
+// EnableDecoderUseNumber is used to call the UseNumber method on the JSON
+// Decoder instance. UseNumber causes the Decoder to unmarshal a number into an
+// any as a Number instead of as a float64.
+var EnableDecoderUseNumber = false
+
+// EnableDecoderDisallowUnknownFields is used to call the DisallowUnknownFields method
+// on the JSON Decoder instance. DisallowUnknownFields causes the Decoder to
+// return an error when the destination is a struct and the input contains object
+// keys which do not match any non-ignored, exported fields in the destination.
+var EnableDecoderDisallowUnknownFields = false
+
+type jsonBinding struct {
+	codec codec.Codec
+}
+
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+func (b jsonBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeJSON(b.codec, req.Body, obj)
+}
+
+func (b jsonBinding) BindBody(body []byte, obj any) error {
+	return decodeJSON(b.codec, bytes.NewReader(body), obj)
+}
+
+// WithCodec returns the JSON binding decoding with c instead of encoding/json,
+// any other binding is returned as is.
+func WithCodec(b Binding, c codec.Codec) Binding {
+	if jb, ok := b.(jsonBinding); ok {
+		jb.codec = c
+		return jb
+	}
+	return b
+}
+
+func decodeJSON(c codec.Codec, r io.Reader, obj any) error {
+	decoder := json.Codec(c).NewDecoder(r)
+	if d, ok := decoder.(interface{ UseNumber() }); ok && EnableDecoderUseNumber {
+		d.UseNumber()
+	}
+	if d, ok := decoder.(interface{ DisallowUnknownFields() }); ok && EnableDecoderDisallowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+	return jsonError(decoder.Decode(obj))
+}
+
+// jsonError reports the JSON values of the wrong type and the unknown fields as FieldErrors.
+func jsonError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		return FieldErrors{{
+			Field:    ute.Field,
+			Source:   SourceBody,
+			Expected: ute.Type.String(),
+			Reason:   "cannot use a JSON " + ute.Value,
+			Err:      err,
+		}}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if name, err := strconv.Unquote(field); err == nil {
+			field = name
+		}
+		return FieldErrors{{Field: field, Source: SourceBody, Reason: "unknown field", Err: err}}
+	}
+	if errors.Is(err, io.EOF) {
+		return errors.New("binding: empty body")
+	}
+	return err
+}
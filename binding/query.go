@@ -0,0 +1,18 @@
+package binding
+
+import "net/http"
+
+type queryBinding struct {
+	tagged bool
+}
+
+func (queryBinding) Name() string {
+	return "query"
+}
+
+func (b queryBinding) Bind(req *http.Request, obj any) error {
+	if b.tagged {
+		return mapping(obj, formSource(req.URL.Query()), SourceQuery, "form", false)
+	}
+	return mapForm(obj, req.URL.Query(), SourceQuery)
+}
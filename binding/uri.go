@@ -0,0 +1,12 @@
+package binding
+
+type uriBinding struct{}
+
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+// BindUri binds the fields tagged with `uri` from the path parameters.
+func (uriBinding) BindUri(m map[string][]string, obj any) error {
+	return mapping(obj, formSource(m), SourceURI, "uri", false)
+}
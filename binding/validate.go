@@ -0,0 +1,401 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrInvalidTag is wrapped by the errors of Validate for an invalid `validate` tag,
+// which is a programming error rather than an invalid request.
+var ErrInvalidTag = errors.New("binding: invalid validate tag")
+
+// Validator is implemented by types with custom validation rules.
+// Validate is called by the Validate function once the `validate` tags pass.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError describes a field that does not satisfy a rule.
+type ValidationError struct {
+	// Field is the path of the field, such as "items[0].name", named after
+	// the json, form, uri or header tag of each field, or its name.
+	Field string `json:"field"`
+	// Rule is the failed rule of the `validate` tag, or "custom" for a Validator.
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, such as "64" for "max=64".
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors is the error returned by Validate.
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the struct pointed to by obj against the `validate` tags of its fields,
+// as in `validate:"required,min=1,max=64"`, then calls the Validate method of the values
+// implementing Validator. Nested structs and the elements of slices and arrays of structs
+// are validated too, and the fields of embedded structs as fields of the embedding struct,
+// as they are in JSON. It returns ValidationErrors if any rule fails.
+//
+// The rules are:
+//
+//	required      the value is not zero, nor a nil pointer or an empty slice or map
+//	omitempty     the other rules are skipped if the value is zero
+//	min=N, max=N  bounds of a number, or of the length of a string, slice or map
+//	len=N         exact length of a string, slice or map, or value of a number
+//	gt, gte, lt, lte=N  exclusive and inclusive bounds, like min and max
+//	oneof=a b c   the value is one of the space-separated values
+//	email         the value is an email address
+//	url           the value is an absolute URL
+//	regexp=RE     the value matches the regular expression, which must not contain commas
+func Validate(obj any) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	var errs ValidationErrors
+	if err := validateValue(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateValue validates the nested structs of v and calls its Validate method.
+func validateValue(v reflect.Value, path string, errs *ValidationErrors) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateValue(v.Elem(), path, errs)
+	case reflect.Struct:
+		if v.Type() != timeType {
+			if err := validateStruct(v, path, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if isNestedStruct(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				if err := validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	callValidator(v, path, errs)
+	return nil
+}
+
+func callValidator(v reflect.Value, path string, errs *ValidationErrors) {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return
+	}
+	val, ok := v.Interface().(Validator)
+	if !ok {
+		return
+	}
+	err := val.Validate()
+	if err == nil {
+		return
+	}
+	var ves ValidationErrors
+	if errors.As(err, &ves) {
+		for _, ve := range ves {
+			fe := *ve
+			fe.Field = joinPath(path, fe.Field)
+			*errs = append(*errs, &fe)
+		}
+		return
+	}
+	*errs = append(*errs, &ValidationError{Field: path, Rule: "custom", Message: err.Error()})
+}
+
+func validateStruct(v reflect.Value, path string, errs *ValidationErrors) error {
+	fields, err := cachedFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		fpath := path
+		if !f.embedded {
+			fpath = joinPath(path, f.name)
+		}
+		if ok := checkRules(fv, fpath, f.rules, errs); !ok {
+			continue
+		}
+		if f.embedded {
+			err = validateEmbedded(fv, fpath, errs)
+		} else {
+			err = validateValue(fv, fpath, errs)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEmbedded validates the fields of an embedded struct, which are promoted
+// to the path of the embedding struct. Its Validate method is not called, it is
+// promoted to the embedding struct as well unless the latter has its own.
+func validateEmbedded(v reflect.Value, path string, errs *ValidationErrors) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return validateStruct(v, path, errs)
+}
+
+// checkRules reports whether fv satisfies its rules, appending an error for the first failed one.
+func checkRules(fv reflect.Value, path string, rules []rule, errs *ValidationErrors) bool {
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			if isEmpty(fv) {
+				*errs = append(*errs, &ValidationError{Field: path, Rule: r.name, Message: "is required"})
+				return false
+			}
+			continue
+		case "omitempty":
+			if isEmpty(fv) {
+				return true
+			}
+			continue
+		}
+
+		v := fv
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return true
+			}
+			v = v.Elem()
+		}
+		if msg, ok := r.check(v); !ok {
+			*errs = append(*errs, &ValidationError{Field: path, Rule: r.name, Param: r.param, Message: msg})
+			return false
+		}
+	}
+	return true
+}
+
+type rule struct {
+	name  string
+	param string
+	num   float64
+	re    *regexp.Regexp
+}
+
+type fieldRules struct {
+	index int
+	name  string
+	rules []rule
+	// embedded is set for the untagged embedded structs, whose fields are promoted
+	// like in encoding/json.
+	embedded bool
+}
+
+var fieldsCache sync.Map // map[reflect.Type][]fieldRules
+
+// cachedFields parses the `validate` tags of the exported fields of t
+// and of its embedded structs.
+func cachedFields(t reflect.Type) ([]fieldRules, error) {
+	if fields, ok := fieldsCache.Load(t); ok {
+		return fields.([]fieldRules), nil
+	}
+
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		name, tagged := fieldName(sf)
+		embedded := sf.Anonymous && !tagged && isNestedStruct(sf.Type)
+		if (!sf.IsExported() && !embedded) || tag == "-" {
+			continue
+		}
+		rules, err := parseRules(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %s of %s: %w", ErrInvalidTag, sf.Name, t, err)
+		}
+		fields = append(fields, fieldRules{index: i, name: name, rules: rules, embedded: embedded})
+	}
+	fieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// fieldName returns the name of the field in the request and whether it is set by a tag.
+func fieldName(sf reflect.StructField) (name string, tagged bool) {
+	for _, tag := range []string{"json", "form", "uri", "header", "xml"} {
+		if name, _ := parseTag(sf.Tag.Get(tag)); name != "" && name != "-" {
+			return name, true
+		}
+	}
+	return sf.Name, false
+}
+
+func parseRules(tag string) ([]rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var rules []rule
+	for _, s := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(s), "=")
+		r := rule{name: name, param: param}
+		switch name {
+		case "required", "omitempty", "email", "url", "oneof":
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter of rule %q: %w", name, err)
+			}
+			r.num = n
+		case "regexp":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter of rule %q: %w", name, err)
+			}
+			r.re = re
+		default:
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// check reports whether v satisfies r and the error message if it does not.
+func (r rule) check(v reflect.Value) (string, bool) {
+	switch r.name {
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		n, isLen, ok := measure(v)
+		if !ok {
+			return "has an unsupported type", false
+		}
+		return compare(r.name, r.param, r.num, n, isLen)
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, opt := range strings.Fields(r.param) {
+			if s == opt {
+				return "", true
+			}
+		}
+		return "must be one of [" + r.param + "]", false
+	case "email":
+		s := fmt.Sprint(v.Interface())
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return "must be a valid email address", false
+		}
+	case "url":
+		u, err := url.Parse(fmt.Sprint(v.Interface()))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL", false
+		}
+	case "regexp":
+		if !r.re.MatchString(fmt.Sprint(v.Interface())) {
+			return "must match " + r.param, false
+		}
+	}
+	return "", true
+}
+
+// measure returns the number compared by the bound rules: the value of numbers,
+// the length of strings in runes and the length of slices, arrays and maps.
+func measure(v reflect.Value) (n float64, isLen, ok bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	}
+	return 0, false, false
+}
+
+func compare(name, param string, bound, n float64, isLen bool) (string, bool) {
+	subject := "must be"
+	if isLen {
+		subject = "length must be"
+	}
+	switch name {
+	case "min", "gte":
+		if n < bound {
+			return subject + " at least " + param, false
+		}
+	case "max", "lte":
+		if n > bound {
+			return subject + " at most " + param, false
+		}
+	case "len":
+		if n != bound {
+			return subject + " " + param, false
+		}
+	case "gt":
+		if n <= bound {
+			return subject + " greater than " + param, false
+		}
+	case "lt":
+		if n >= bound {
+			return subject + " less than " + param, false
+		}
+	}
+	return "", true
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func joinPath(path, field string) string {
+	switch {
+	case path == "":
+		return field
+	case field == "", strings.HasPrefix(field, "["):
+		return path + field
+	}
+	return path + "." + field
+}
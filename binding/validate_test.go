@@ -0,0 +1,276 @@
+package binding
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type ruleCase struct {
+	name string
+	obj  any
+	// rule is the failed rule, or empty if the value is valid.
+	rule string
+}
+
+func TestValidateRules(t *testing.T) {
+	type required struct {
+		S string            `validate:"required"`
+		P *int              `validate:"required"`
+		L []int             `validate:"required"`
+		M map[string]string `validate:"required"`
+	}
+	type omitempty struct {
+		S string `validate:"omitempty,min=3"`
+	}
+	type str struct {
+		S string `validate:"min=2,max=4"`
+	}
+	type strLen struct {
+		S string `validate:"len=3"`
+	}
+	type num struct {
+		N int `validate:"gt=0,lt=10"`
+	}
+	type numMin struct {
+		F float64 `validate:"min=1.5,max=2.5"`
+	}
+	type numInclusive struct {
+		U uint `validate:"gte=1,lte=1"`
+	}
+	type slice struct {
+		L []string `validate:"min=1,max=2"`
+	}
+	type sliceLen struct {
+		L []int `validate:"len=2"`
+	}
+	type oneof struct {
+		S string `validate:"oneof=red green"`
+		N int    `validate:"omitempty,oneof=1 2"`
+	}
+	type email struct {
+		S string `validate:"email"`
+	}
+	type url struct {
+		S string `validate:"url"`
+	}
+	type re struct {
+		S string `validate:"regexp=^[a-z]+$"`
+	}
+	type pointer struct {
+		P *string `validate:"min=2"`
+	}
+	one := 1
+
+	tests := []ruleCase{
+		{"required ok", required{S: "a", P: &one, L: []int{0}, M: map[string]string{"": ""}}, ""},
+		{"required string", required{P: &one, L: []int{0}, M: map[string]string{"": ""}}, "required"},
+		{"required nil pointer", required{S: "a", L: []int{0}, M: map[string]string{"": ""}}, "required"},
+		{"required empty slice", required{S: "a", P: &one, L: []int{}, M: map[string]string{"": ""}}, "required"},
+		{"required empty map", required{S: "a", P: &one, L: []int{0}}, "required"},
+		{"omitempty zero", omitempty{}, ""},
+		{"omitempty set", omitempty{S: "ab"}, "min"},
+		{"string min", str{S: "a"}, "min"},
+		{"string max", str{S: "abcde"}, "max"},
+		{"string runes", str{S: "éèêë"}, ""},
+		{"string len", strLen{S: "ab"}, "len"},
+		{"string len ok", strLen{S: "abc"}, ""},
+		{"number gt", num{N: 0}, "gt"},
+		{"number lt", num{N: 10}, "lt"},
+		{"number ok", num{N: 5}, ""},
+		{"float min", numMin{F: 1.4}, "min"},
+		{"float max", numMin{F: 2.6}, "max"},
+		{"float ok", numMin{F: 2.5}, ""},
+		{"uint gte", numInclusive{U: 0}, "gte"},
+		{"uint lte", numInclusive{U: 2}, "lte"},
+		{"uint ok", numInclusive{U: 1}, ""},
+		{"slice min", slice{L: []string{}}, "min"},
+		{"slice max", slice{L: []string{"a", "b", "c"}}, "max"},
+		{"slice ok", slice{L: []string{"a"}}, ""},
+		{"slice len", sliceLen{L: []int{1}}, "len"},
+		{"oneof ok", oneof{S: "red", N: 2}, ""},
+		{"oneof string", oneof{S: "blue"}, "oneof"},
+		{"oneof number", oneof{S: "red", N: 3}, "oneof"},
+		{"email ok", email{S: "gopher@example.com"}, ""},
+		{"email name", email{S: "Gopher <gopher@example.com>"}, "email"},
+		{"email invalid", email{S: "gopher"}, "email"},
+		{"url ok", url{S: "https://example.com/a"}, ""},
+		{"url relative", url{S: "/a"}, "url"},
+		{"regexp ok", re{S: "abc"}, ""},
+		{"regexp", re{S: "Abc"}, "regexp"},
+		{"nil pointer skips the rules", pointer{}, ""},
+		{"pointer", pointer{P: new(string)}, "min"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkRule(t, tt)
+		})
+	}
+}
+
+func checkRule(t *testing.T, tt ruleCase) {
+	t.Helper()
+	err := Validate(tt.obj)
+	if tt.rule == "" {
+		if err != nil {
+			t.Fatalf("Validate(%+v) = %v, want nil", tt.obj, err)
+		}
+		return
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Validate(%+v) = %v, want one ValidationError", tt.obj, err)
+	}
+	if errs[0].Rule != tt.rule {
+		t.Errorf("rule = %q, want %q", errs[0].Rule, tt.rule)
+	}
+}
+
+func TestValidateNestedPath(t *testing.T) {
+	type item struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type order struct {
+		Items []item `json:"items" validate:"min=1"`
+		Ship  *item  `json:"ship"`
+	}
+
+	err := Validate(&order{Items: []item{{Name: "a"}, {}}, Ship: &item{}})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	var fields []string
+	for _, fe := range errs {
+		fields = append(fields, fe.Field)
+	}
+	if len(fields) != 2 || fields[0] != "items[1].name" || fields[1] != "ship.name" {
+		t.Errorf("fields = %q, want [items[1].name ship.name]", fields)
+	}
+
+	if err := Validate((*order)(nil)); err != nil {
+		t.Errorf("Validate(nil) = %v, want nil", err)
+	}
+}
+
+type valueValidator struct {
+	N int `json:"n"`
+}
+
+func (v valueValidator) Validate() error {
+	if v.N%2 != 0 {
+		return errors.New("must be even")
+	}
+	return nil
+}
+
+type pointerValidator struct {
+	S string `json:"s" validate:"required"`
+}
+
+func (v *pointerValidator) Validate() error {
+	if v.S == "admin" {
+		return ValidationErrors{{Field: "s", Rule: "reserved", Message: "is reserved"}}
+	}
+	return nil
+}
+
+func TestValidateValidator(t *testing.T) {
+	type form struct {
+		Value   valueValidator     `json:"value"`
+		Pointer *pointerValidator  `json:"pointer"`
+		List    []pointerValidator `json:"list"`
+	}
+
+	tests := []struct {
+		name  string
+		obj   any
+		field string
+		rule  string
+	}{
+		{"value receiver", &form{Value: valueValidator{N: 1}}, "value", "custom"},
+		{"value receiver on a value", valueValidator{N: 1}, "", "custom"},
+		{"pointer receiver", &form{Pointer: &pointerValidator{S: "admin"}}, "pointer.s", "reserved"},
+		{"pointer receiver in a slice", &form{List: []pointerValidator{{S: "a"}, {S: "admin"}}}, "list[1].s", "reserved"},
+		{"tags before Validate", &form{Pointer: &pointerValidator{}}, "pointer.s", "required"},
+		{"valid", &form{Value: valueValidator{N: 2}, Pointer: &pointerValidator{S: "a"}}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.obj)
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("Validate() = %v, want one ValidationError", err)
+			}
+			if errs[0].Field != tt.field || errs[0].Rule != tt.rule {
+				t.Errorf("error = %s %s, want %s %s", errs[0].Field, errs[0].Rule, tt.field, tt.rule)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	type unknown struct {
+		S string `validate:"uuid"`
+	}
+	type param struct {
+		N int `validate:"min=a"`
+	}
+	for _, obj := range []any{unknown{}, param{}} {
+		if err := Validate(obj); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("Validate(%T) = %v, want ErrInvalidTag", obj, err)
+		}
+	}
+}
+
+type base struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type Audit struct {
+	By string `json:"by" validate:"required"`
+}
+
+func TestValidateEmbedded(t *testing.T) {
+	type named struct {
+		Audit `json:"audit"`
+	}
+	type doc struct {
+		base
+		*Audit
+		Title string `json:"title" validate:"required"`
+	}
+
+	tests := []struct {
+		name   string
+		obj    any
+		fields []string
+	}{
+		{"promoted fields", &doc{Audit: &Audit{}}, []string{"id", "by", "title"}},
+		{"nil embedded pointer", &doc{base: base{ID: "1"}, Title: "a"}, nil},
+		{"tagged embedded struct", &named{}, []string{"audit.by"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.obj)
+			var fields []string
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				for _, fe := range errs {
+					fields = append(fields, fe.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type xmlBinding struct{}
+
+func (xmlBinding) Name() string {
+	return "xml"
+}
+
+func (xmlBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeXML(req.Body, obj)
+}
+
+func (xmlBinding) BindBody(body []byte, obj any) error {
+	return decodeXML(bytes.NewReader(body), obj)
+}
+
+func decodeXML(r io.Reader, obj any) error {
+	tr := &xmlTracker{d: xml.NewDecoder(r)}
+	return xmlError(xml.NewTokenDecoder(tr).Decode(obj), tr)
+}
+
+// xmlTracker is an xml.TokenReader tracking the path of the element being decoded,
+// so that the errors of the values name their field.
+type xmlTracker struct {
+	d     *xml.Decoder
+	stack []string
+	// field is the path of the element whose value is being decoded: the element
+	// just ended for its text, or the element just started for its attributes.
+	field string
+}
+
+// Token implements the xml.TokenReader interface.
+func (t *xmlTracker) Token() (xml.Token, error) {
+	tok, err := t.d.Token()
+	switch tok := tok.(type) {
+	case xml.StartElement:
+		t.stack = append(t.stack, tok.Name.Local)
+		t.field = t.path()
+	case xml.EndElement:
+		t.field = t.path()
+		if len(t.stack) > 0 {
+			t.stack = t.stack[:len(t.stack)-1]
+		}
+	}
+	return tok, err
+}
+
+// path returns the path of the current element without the root element,
+// which is the bound value, such as "address.city".
+func (t *xmlTracker) path() string {
+	if len(t.stack) <= 1 {
+		return ""
+	}
+	return strings.Join(t.stack[1:], ".")
+}
+
+func xmlError(err error, t *xmlTracker) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, io.EOF) {
+		return errors.New("binding: empty body")
+	}
+	var se *xml.SyntaxError
+	if errors.As(err, &se) {
+		return err
+	}
+	if t.field == "" {
+		return err
+	}
+	// The value of the field cannot be converted to its type.
+	return FieldErrors{{Field: t.field, Source: SourceBody, Reason: reason(err), Err: err}}
+}
@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestXMLFieldErrors(t *testing.T) {
+	type address struct {
+		Zip int `xml:"zip"`
+	}
+	type user struct {
+		Name    string  `xml:"name"`
+		Age     int     `xml:"age"`
+		ID      int     `xml:"id,attr"`
+		Address address `xml:"address"`
+	}
+
+	tests := []struct {
+		body  string
+		field string
+	}{
+		{`<user><name>eve</name><age>old</age></user>`, "age"},
+		{`<user><address><zip>x</zip></address></user>`, "address.zip"},
+	}
+	for _, tt := range tests {
+		var u user
+		err := XML.BindBody([]byte(tt.body), &u)
+		var fes FieldErrors
+		if !errors.As(err, &fes) {
+			t.Errorf("%s: error %v is not a FieldErrors", tt.body, err)
+			continue
+		}
+		if fes[0].Field != tt.field || fes[0].Source != SourceBody {
+			t.Errorf("%s: field %q from %q, want %q from body", tt.body, fes[0].Field, fes[0].Source, tt.field)
+		}
+	}
+
+	var u user
+	if err := XML.BindBody([]byte(`<user><name>`), &u); err == nil || strings.Contains(err.Error(), "binding: body") {
+		t.Errorf("syntax error reported as %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package plum
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which render buffers are not pooled.
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// bufferedWriter collects the body written by a render, the headers are those of the response.
+type bufferedWriter struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// WriteHeader is a no-op, the status is written once the render succeeds.
+func (w *bufferedWriter) WriteHeader(int) {}
@@ -0,0 +1,94 @@
+package plum
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-plum/plum/render"
+)
+
+// failingRender writes a part of its body then fails.
+type failingRender struct{}
+
+func (failingRender) Render(w http.ResponseWriter) error {
+	_, _ = io.WriteString(w, "partial")
+	return errors.New("render failed")
+}
+
+func (failingRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+}
+
+func newBufferedEngine() *Plum {
+	return New(WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), BufferedRender(true))
+}
+
+func TestBufferedRenderFailure(t *testing.T) {
+	p := newBufferedEngine()
+	p.GET("/", func(c *Context) {
+		c.Render(http.StatusOK, failingRender{})
+	})
+
+	w := serve(p, http.MethodGet, "/")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("body = %q, want no partial body", w.Body.String())
+	}
+}
+
+func TestBufferedRenderContentLength(t *testing.T) {
+	p := newBufferedEngine()
+	p.GET("/", func(c *Context) {
+		c.JSON(http.StatusCreated, map[string]int{"a": 1})
+	})
+
+	w := serve(p, http.MethodGet, "/")
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if got, want := w.Header().Get("Content-Length"), "7"; got != want {
+		t.Errorf("Content-Length = %q, want %q", got, want)
+	}
+	if got, want := w.Body.String(), `{"a":1}`; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestUnbufferedRenderFailure(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/", func(c *Context) {
+		c.Render(http.StatusOK, failingRender{})
+	})
+
+	w := serve(p, http.MethodGet, "/")
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want %d %q", w.Code, w.Body.String(), http.StatusOK, "partial")
+	}
+}
+
+func TestIsBufferable(t *testing.T) {
+	seq := func(yield func([]string) bool) {}
+	tests := []struct {
+		name string
+		r    render.Render
+		want bool
+	}{
+		{"json", render.JSON{Data: 1}, true},
+		{"string", render.String{Format: "a"}, true},
+		{"csv", render.CSV{Rows: [][]string{{"a"}}}, true},
+		{"csv with seq", render.CSV{Seq: seq}, false},
+		{"redirect", render.Redirect{Code: http.StatusFound, Location: "/"}, false},
+		{"reader", render.Reader{Reader: strings.NewReader("a"), ContentLength: -1}, false},
+	}
+	for _, tt := range tests {
+		if got := isBufferable(tt.r); got != tt.want {
+			t.Errorf("isBufferable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package codec defines the interface of the JSON codec used by the bindings
+// and the renderers, see plum.WithJSONCodec.
+package codec
+
+import "io"
+
+// Encoder writes JSON values to an output stream.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads JSON values from an input stream.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec marshals and unmarshals JSON. The encoders may also implement
+// SetEscapeHTML(bool), and the decoders UseNumber() and DisallowUnknownFields(),
+// like those of encoding/json.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
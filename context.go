@@ -1,7 +1,9 @@
 package plum
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"mime/multipart"
@@ -10,6 +12,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,14 +30,18 @@ const abortIndex int8 = math.MaxInt8 >> 1
 // manage the flow,
 // validate the JSON of a request and render a JSON response for example.
 type Context struct {
-	Request *http.Request
-	Writer  http.ResponseWriter
+	writermem responseWriter
+	Request   *http.Request
+	Writer    ResponseWriter
 
 	Params   Params
 	handlers []HandlerFunc
 	index    int8
+	fullPath string
 
 	engine *Plum
+	// handlerName receives the name of the handler wrapped by E, see nameOfHandler.
+	handlerName *string
 
 	// This mutex protects Keys map.
 	mu sync.RWMutex
@@ -50,9 +58,11 @@ type Context struct {
 /************************************/
 
 func (c *Context) reset() {
+	c.Writer = &c.writermem
 	c.Params = c.Params[:0]
 	c.handlers = nil
 	c.index = -1
+	c.fullPath = ""
 	c.Keys = nil
 	c.sameSite = 0
 }
@@ -61,11 +71,15 @@ func (c *Context) reset() {
 // This has to be used when the context has to be passed to a goroutine.
 func (c *Context) Copy() *Context {
 	cp := Context{
-		Request: c.Request,
-		engine:  c.engine,
+		writermem: c.writermem,
+		Request:   c.Request,
+		engine:    c.engine,
 	}
+	cp.writermem.ResponseWriter = nil
+	cp.Writer = &cp.writermem
 
 	cp.index = abortIndex
+	cp.fullPath = c.fullPath
 	// cp.handlers = nil
 
 	cKeys := c.Keys
@@ -126,6 +140,13 @@ func (c *Context) AbortWithStatusJSON(code int, jsonObj any) {
 	c.JSON(code, jsonObj)
 }
 
+// Error passes err to the ErrorHandler of the engine, which writes the response,
+// and aborts the chain.
+func (c *Context) Error(err error) {
+	c.Abort()
+	c.engine.opts.errorHandler(c, err)
+}
+
 /************************************/
 /******** METADATA MANAGEMENT********/
 /************************************/
@@ -155,6 +176,12 @@ func (c *Context) Get(key string) (value any, exists bool) {
 /************ INPUT DATA ************/
 /************************************/
 
+// FullPath returns the pattern path of the matched route, such as "/users/{id}",
+// or "" if no route matched.
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 // Param returns the value of the URL param.
 // It is a shortcut for c.Params.ByName(key)
 func (c *Context) Param(key string) string {
@@ -297,21 +324,109 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 	return err
 }
 
+// Bind is like ShouldBind, but it aborts the request with a problem details body
+// if any error occurs, see MustBindWith.
+func (c *Context) Bind(obj any) error {
+	if err := c.ShouldBind(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
 // MustBindWith binds the passed struct pointer using the specified binding engine.
-// It will abort the request with HTTP 400 if any error occurs.
+// It will abort the request with a problem details body, see BindingProblem,
+// HTTP 422 if the validation fails, HTTP 400 if any other error occurs.
 // See the binding package.
 func (c *Context) MustBindWith(obj any, b binding.Binding) error {
 	if err := c.ShouldBindWith(obj, b); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, err)
+		c.abortWithBindError(err)
 		return err
 	}
 	return nil
 }
 
-// ShouldBindWith binds the passed struct pointer using the specified binding engine.
+// abortWithBindError writes the binding error as a problem, or passes an invalid
+// `validate` tag to the error handler so that it is not reported to the client.
+func (c *Context) abortWithBindError(err error) {
+	if errors.Is(err, binding.ErrInvalidTag) {
+		c.Error(err)
+		return
+	}
+	c.AbortWithProblem(BindingProblem(err))
+}
+
+// ShouldBind binds all the sources of the request to the passed struct pointer:
+// the body according to the method and the Content-Type, see binding.Default, then
+// the path parameters to the fields tagged with `uri` and the headers to the fields
+// tagged with `header`, which take precedence over the query and the body.
+// The query is bound with the form, or before a JSON or XML body to the fields
+// tagged with `form` only, so that a body field cannot be set from the query.
+// Any other pointer, such as a map pointer, is only bound to the body.
+func (c *Context) ShouldBind(obj any) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	if !isStructPointer(obj) {
+		return c.ShouldBindWith(obj, b)
+	}
+	if _, isBody := b.(binding.BindingBody); isBody {
+		if err := binding.QueryTagged.Bind(c.Request, obj); err != nil {
+			return err
+		}
+	}
+	if err := binding.WithCodec(b, c.engine.opts.jsonCodec).Bind(c.Request, obj); err != nil {
+		return err
+	}
+	if len(c.Params) > 0 {
+		if err := binding.Uri.BindUri(c.paramsMap(), obj); err != nil {
+			return err
+		}
+	}
+	if err := binding.Header.Bind(c.Request, obj); err != nil {
+		return err
+	}
+	return binding.Validate(obj)
+}
+
+// ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, binding.Query).
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, binding.Header).
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBindWith(obj, binding.Header)
+}
+
+// ShouldBindUri binds the path parameters to the passed struct pointer using binding.Uri.
+func (c *Context) ShouldBindUri(obj any) error {
+	if err := binding.Uri.BindUri(c.paramsMap(), obj); err != nil {
+		return err
+	}
+	return binding.Validate(obj)
+}
+
+func isStructPointer(obj any) bool {
+	t := reflect.TypeOf(obj)
+	return t != nil && t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct
+}
+
+func (c *Context) paramsMap() map[string][]string {
+	m := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		m[p.Key] = []string{p.Value}
+	}
+	return m
+}
+
+// ShouldBindWith binds the passed struct pointer using the specified binding engine,
+// then validates it with binding.Validate.
 // See the binding package.
 func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
-	return b.Bind(c.Request, obj)
+	b = binding.WithCodec(b, c.engine.opts.jsonCodec)
+	if err := b.Bind(c.Request, obj); err != nil {
+		return err
+	}
+	return binding.Validate(obj)
 }
 
 // ShouldBindBodyWith is similar with ShouldBindWith, but it stores the request
@@ -333,7 +448,11 @@ func (c *Context) ShouldBindBodyWith(bodyKey string, obj any, bb binding.Binding
 		}
 		c.Set(bodyKey, body)
 	}
-	return bb.BindBody(body, obj)
+	bb = binding.WithCodec(bb, c.engine.opts.jsonCodec).(binding.BindingBody)
+	if err = bb.BindBody(body, obj); err != nil {
+		return err
+	}
+	return binding.Validate(obj)
 }
 
 // RemoteIP parses the IP from Request.RemoteAddr, normalizes and returns the IP (without the port).
@@ -345,6 +464,109 @@ func (c *Context) RemoteIP() string {
 	return ip
 }
 
+// ClientIP returns the IP of the client. When the request comes from one of the
+// TrustedProxies, it walks the RemoteIPHeaders right-to-left and returns the first IP
+// which is not a trusted proxy. Otherwise, it returns the RemoteIP.
+func (c *Context) ClientIP() string {
+	remote, ok := parseNode(c.Request.RemoteAddr)
+	if !ok {
+		return c.RemoteIP()
+	}
+	if !prefixesContain(c.engine.opts.trustedProxies, remote) {
+		return remote.String()
+	}
+
+	for _, name := range c.engine.RemoteIPHeaders {
+		lines := c.Request.Header.Values(name)
+		if len(lines) == 0 {
+			continue
+		}
+		var values []string
+		if strings.EqualFold(name, "Forwarded") {
+			values = forwardedValues(lines, "for")
+		} else {
+			values = headerValues(lines)
+		}
+		for i := len(values) - 1; i >= 0; i-- {
+			ip, ok := parseNode(values[i])
+			if !ok {
+				break
+			}
+			if i == 0 || !prefixesContain(c.engine.opts.trustedProxies, ip) {
+				return ip.String()
+			}
+		}
+	}
+	return remote.String()
+}
+
+// Scheme returns the scheme, "http" or "https", of the request as sent by the client,
+// read from the Forwarded or X-Forwarded-Proto header when the request comes
+// from one of the TrustedProxies.
+func (c *Context) Scheme() string {
+	if c.fromTrustedProxy() {
+		proto := strings.ToLower(c.forwardedHeader("proto", "X-Forwarded-Proto"))
+		if proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host returns the host of the request as sent by the client, read from the Forwarded
+// or X-Forwarded-Host header when the request comes from one of the TrustedProxies.
+func (c *Context) Host() string {
+	if c.fromTrustedProxy() {
+		if host := c.forwardedHeader("host", "X-Forwarded-Host"); host != "" {
+			return host
+		}
+	}
+	return c.Request.Host
+}
+
+// ProxyHeader returns the PROXY protocol header of the connection, or nil if the server
+// does not read them or the connection has none, see WithProxyProtocol and ProxyListener.
+// The RemoteIP is already the one of the original connection.
+func (c *Context) ProxyHeader() *ProxyHeader {
+	pc, ok := c.Request.Context().Value(proxyConnKey{}).(*proxyConn)
+	if !ok {
+		return nil
+	}
+	pc.readHeader()
+	return pc.header
+}
+
+func (c *Context) fromTrustedProxy() bool {
+	ip, ok := parseNode(c.Request.RemoteAddr)
+	return ok && prefixesContain(c.engine.opts.trustedProxies, ip)
+}
+
+// forwardedHeader returns the key of the Forwarded element added by the last trusted
+// proxy, found by walking the elements right-to-left like ClientIP, or the last value
+// of the header if there is no Forwarded header. The values on the left are sent
+// by the client and can not be trusted.
+func (c *Context) forwardedHeader(key, header string) string {
+	if lines := c.Request.Header.Values("Forwarded"); len(lines) > 0 {
+		nodes := forwardedValues(lines, "for")
+		i := len(nodes) - 1
+		for ; i > 0; i-- {
+			ip, ok := parseNode(nodes[i])
+			if !ok || !prefixesContain(c.engine.opts.trustedProxies, ip) {
+				break
+			}
+		}
+		return forwardedValues(lines, key)[i]
+	}
+	values := headerValues(c.Request.Header.Values(header))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
 func filterFlags(content string) string {
 	for i, char := range content {
 		if char == ' ' || char == ';' {
@@ -457,20 +679,62 @@ func (c *Context) Cookie(name string) (string, error) {
 }
 
 // Render writes the response headers and calls render.Render to render data.
+// In buffered render mode, see BufferedRender, the data is rendered before
+// the headers are written, so that a failure is turned into a 500 by the ErrorHandler.
+// A render.MapJSON is marshaled with the JSON codec of the engine, see WithJSONCodec.
 func (c *Context) Render(code int, r render.Render) {
+	if m, ok := r.(render.MapJSON); ok {
+		r = render.JSON{Data: map[string]any(m), Codec: c.engine.opts.jsonCodec}
+	}
+	if c.engine.opts.bufferedRender && bodyAllowedForStatus(code) && isBufferable(r) {
+		c.renderBuffered(code, r)
+		return
+	}
+
+	r.WriteContentType(c.Writer)
 	c.Status(code)
 	if !bodyAllowedForStatus(code) {
-		r.WriteContentType(c.Writer)
 		return
 	}
 
 	if err := r.Render(c.Writer); err != nil {
-		// Pushing error to c.Errors
-		c.engine.opts.Log.Error("Render error %+v", err)
+		c.engine.opts.Log.Error("plum: render error", "method", c.Request.Method,
+			"path", c.Request.URL.Path, "error", err)
 		c.Abort()
 	}
 }
 
+func (c *Context) renderBuffered(code int, r render.Render) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := r.Render(&bufferedWriter{ResponseWriter: c.Writer, buf: buf}); err != nil {
+		c.Writer.Header().Del("Content-Type")
+		c.Error(fmt.Errorf("plum: render: %w", err))
+		return
+	}
+	c.Header("Content-Length", strconv.Itoa(buf.Len()))
+	c.Status(code)
+	_, _ = c.Writer.Write(buf.Bytes())
+}
+
+// isBufferable reports whether r is rendered into a buffer in buffered render mode,
+// which excludes the redirects and the streams.
+func isBufferable(r render.Render) bool {
+	switch r := r.(type) {
+	case render.Redirect, render.Reader:
+		return false
+	case render.CSV:
+		return r.Seq == nil
+	}
+	return true
+}
+
 // HTML renders the HTTP template specified by its file name.
 // It also updates the HTTP code and sets the Content-Type as "text/html".
 // See http://golang.org/doc/articles/wiki/
@@ -485,16 +749,62 @@ func (c *Context) HTML(code int, name string, obj any) {
 func (c *Context) JSONP(code int, obj any) {
 	callback := c.DefaultQuery("callback", "")
 	if callback == "" {
-		c.Render(code, render.JSON{Data: obj})
+		c.Render(code, render.JSON{Data: obj, Codec: c.engine.opts.jsonCodec})
 		return
 	}
-	c.Render(code, render.JsonpJSON{Callback: callback, Data: obj})
+	c.Render(code, render.JsonpJSON{Callback: callback, Data: obj, Codec: c.engine.opts.jsonCodec})
 }
 
 // JSON serializes the given struct as JSON into the response body.
 // It also sets the Content-Type as "application/json".
 func (c *Context) JSON(code int, obj any) {
-	c.Render(code, render.JSON{Data: obj})
+	c.Render(code, render.JSON{Data: obj, Codec: c.engine.opts.jsonCodec})
+}
+
+// IndentedJSON serializes the given struct as pretty JSON (indented + endlines) into the response body.
+// It also sets the Content-Type as "application/json".
+// WARNING: we recommend using this only for development purposes since printing pretty JSON is
+// more CPU and bandwidth consuming. Use Context.JSON() instead.
+func (c *Context) IndentedJSON(code int, obj any) {
+	c.Render(code, render.IndentedJSON{Data: obj, Codec: c.engine.opts.jsonCodec})
+}
+
+// SecureJSON serializes the given struct as Secure JSON into the response body.
+// Default prepends "while(1);" to response body if the given struct is array values.
+// It also sets the Content-Type as "application/json".
+func (c *Context) SecureJSON(code int, obj any) {
+	c.Render(code, render.SecureJSON{Prefix: c.engine.opts.secureJSONPrefix, Data: obj, Codec: c.engine.opts.jsonCodec})
+}
+
+// AsciiJSON serializes the given struct as JSON into the response body with unicode to ASCII string.
+// It also sets the Content-Type as "application/json".
+func (c *Context) AsciiJSON(code int, obj any) {
+	c.Render(code, render.AsciiJSON{Data: obj, Codec: c.engine.opts.jsonCodec})
+}
+
+// PureJSON serializes the given struct as JSON into the response body.
+// PureJSON, unlike JSON, does not replace special html characters with their unicode entities.
+func (c *Context) PureJSON(code int, obj any) {
+	c.Render(code, render.PureJSON{Data: obj, Codec: c.engine.opts.jsonCodec})
+}
+
+// XML serializes the given struct as XML into the response body.
+// It also sets the Content-Type as "application/xml".
+func (c *Context) XML(code int, obj any) {
+	c.Render(code, render.XML{Data: obj})
+}
+
+// CSV writes the rows as comma-separated values into the response body,
+// preceded by the header if it is not nil.
+// It also sets the Content-Type as "text/csv".
+func (c *Context) CSV(code int, header []string, rows [][]string) {
+	c.Render(code, render.CSV{Header: header, Rows: rows})
+}
+
+// CSVSeq is like CSV, but it streams the rows yielded by seq,
+// whose signature is the one of iter.Seq[[]string].
+func (c *Context) CSVSeq(code int, header []string, seq func(yield func([]string) bool)) {
+	c.Render(code, render.CSV{Header: header, Seq: seq})
 }
 
 // String writes the given string into the response body.
@@ -519,6 +829,17 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 	})
 }
 
+// DataFromReader writes the specified reader into the body stream and updates the HTTP code.
+// The contentLength is -1 if it is unknown.
+func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	c.Render(code, render.Reader{
+		Headers:       extraHeaders,
+		ContentType:   contentType,
+		ContentLength: contentLength,
+		Reader:        reader,
+	})
+}
+
 // File writes the specified file into the body stream in an efficient way.
 func (c *Context) File(filepath string) {
 	http.ServeFile(c.Writer, c.Request, filepath)
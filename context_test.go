@@ -0,0 +1,222 @@
+package plum
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-plum/plum/codec"
+	"github.com/go-plum/plum/render"
+)
+
+func TestShouldBindQueryCannotSetBodyFields(t *testing.T) {
+	type user struct {
+		Name    string `json:"name"`
+		IsAdmin bool   `json:"is_admin"`
+		Page    int    `form:"page" json:"-"`
+	}
+
+	p := newTestEngine()
+	var got user
+	p.POST("/users", func(c *Context) {
+		if err := c.ShouldBind(&got); err != nil {
+			t.Error(err)
+		}
+	})
+	req := httptest.NewRequest(http.MethodPost, "/users?IsAdmin=true&is_admin=true&page=2", strings.NewReader(`{"name":"eve"}`))
+	req.Header.Set("Content-Type", "application/json")
+	p.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.IsAdmin {
+		t.Error("IsAdmin is set from the query")
+	}
+	if got.Name != "eve" || got.Page != 2 {
+		t.Errorf("got %+v, want Name eve and Page 2", got)
+	}
+}
+
+func TestShouldBindQueryCannotSetURIOrHeaderFields(t *testing.T) {
+	type user struct {
+		ID   int    `uri:"id"`
+		Auth string `header:"Authorization"`
+		Page int    `form:"page"`
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		p := newTestEngine()
+		var got user
+		p.Handle(method, "/users/{id}", func(c *Context) {
+			if err := c.ShouldBind(&got); err != nil {
+				t.Error(err)
+			}
+		})
+		req := httptest.NewRequest(method, "/users/42?ID=99&Auth=x&page=2", strings.NewReader("ID=98&Auth=y"))
+		req.Header.Set("Authorization", "real")
+		if method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		p.ServeHTTP(httptest.NewRecorder(), req)
+
+		if want := (user{ID: 42, Auth: "real", Page: 2}); got != want {
+			t.Errorf("%s: got %+v, want %+v", method, got, want)
+		}
+	}
+}
+
+func TestBindInvalidValidateTag(t *testing.T) {
+	type form struct {
+		Name string `form:"name" validate:"nope"`
+	}
+
+	p := newTestEngine()
+	p.GET("/", func(c *Context) {
+		var f form
+		if err := c.Bind(&f); err == nil {
+			t.Error("no error for an invalid validate tag")
+		}
+	})
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?name=x", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "nope") {
+		t.Errorf("the tag is reported to the client: %s", w.Body.String())
+	}
+}
+
+func TestForwardedRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		ip      string
+		scheme  string
+		host    string
+	}{
+		{
+			name:    "untrusted remote",
+			remote:  "203.0.113.9:1234",
+			headers: map[string]string{"Forwarded": "for=1.2.3.4;host=evil.com;proto=https", "X-Forwarded-For": "1.2.3.4"},
+			ip:      "203.0.113.9", scheme: "http", host: "example.com",
+		},
+		{
+			name:    "trusted proxy",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=5.6.7.8;host=good.com;proto=https"},
+			ip:      "5.6.7.8", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "spoofed element",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=1.2.3.4;host=evil.com;proto=http, for=5.6.7.8;host=good.com;proto=https"},
+			ip:      "5.6.7.8", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "chain of trusted proxies",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=1.2.3.4;host=evil.com, for=5.6.7.8;host=good.com;proto=https, for=10.0.0.3;host=inner.lan;proto=http, for=10.0.0.2;proto=http"},
+			ip:      "5.6.7.8", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "only trusted proxies",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=10.0.0.3;host=good.com;proto=https, for=10.0.0.2"},
+			ip:      "10.0.0.3", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "obfuscated node",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=1.2.3.4;host=evil.com, for=_hidden;host=good.com;proto=https", "X-Forwarded-For": "5.6.7.8"},
+			ip:      "5.6.7.8", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "unknown node",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=unknown;host=good.com"},
+			ip:      "10.0.0.1", scheme: "http", host: "good.com",
+		},
+		{
+			name:    "ipv6 with a port",
+			remote:  "[fd00::1]:1234",
+			headers: map[string]string{"Forwarded": `for="[2001:db8::17]:4711";host=good.com;proto=https, for="[fd00::2]:80"`},
+			ip:      "2001:db8::17", scheme: "https", host: "good.com",
+		},
+		{
+			name:   "x-forwarded headers",
+			remote: "10.0.0.1:1234",
+			headers: map[string]string{
+				"X-Forwarded-For":   "1.2.3.4, 5.6.7.8, 10.0.0.2",
+				"X-Forwarded-Proto": "http, https",
+				"X-Forwarded-Host":  "evil.com, good.com",
+			},
+			ip: "5.6.7.8", scheme: "https", host: "good.com",
+		},
+		{
+			name:    "x-real-ip",
+			remote:  "10.0.0.1:1234",
+			headers: map[string]string{"X-Real-IP": "5.6.7.8"},
+			ip:      "5.6.7.8", scheme: "http", host: "example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(TrustedProxies("10.0.0.0/8", "fd00::/8"), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			var ip, scheme, host string
+			p.GET("/", func(c *Context) {
+				ip, scheme, host = c.ClientIP(), c.Scheme(), c.Host()
+			})
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			p.ServeHTTP(httptest.NewRecorder(), req)
+
+			if ip != tt.ip || scheme != tt.scheme || host != tt.host {
+				t.Errorf("ClientIP, Scheme, Host = %s %s %s, want %s %s %s", ip, scheme, host, tt.ip, tt.scheme, tt.host)
+			}
+		})
+	}
+}
+
+// upperCodec marshals with encoding/json and upper cases the output.
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	return bytes.ToUpper(b), err
+}
+
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (upperCodec) NewEncoder(w io.Writer) codec.Encoder {
+	return json.NewEncoder(w)
+}
+
+func (upperCodec) NewDecoder(r io.Reader) codec.Decoder {
+	return json.NewDecoder(r)
+}
+
+func TestRenderMapJSONCodec(t *testing.T) {
+	p := New(WithJSONCodec(upperCodec{}), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	p.GET("/", func(c *Context) {
+		c.Render(http.StatusOK, render.MapJSON{"a": "b"})
+	})
+
+	w := serve(p, http.MethodGet, "/")
+	if got, want := w.Body.String(), `{"A":"B"}`; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/json; charset=utf-8"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+}
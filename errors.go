@@ -0,0 +1,96 @@
+package plum
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// HandlerFuncE is a handler that returns an error, see E.
+type HandlerFuncE func(*Context) error
+
+// E adapts a handler that returns an error to a HandlerFunc.
+// A non-nil error is passed to Context.Error. The routes report the name of h
+// as their Handler, see RouteInfo.
+func E(h HandlerFuncE) HandlerFunc {
+	return func(c *Context) {
+		if c.handlerName != nil {
+			*c.handlerName = nameOfFunction(h)
+			return
+		}
+		if err := h(c); err != nil {
+			c.Error(err)
+		}
+	}
+}
+
+// nameOfE is the name of the handlers returned by E.
+var nameOfE = nameOfFunction(E(nil))
+
+// nameOfHandler returns the name of the handler, or of the one wrapped by E.
+func nameOfHandler(h HandlerFunc) string {
+	name := nameOfFunction(h)
+	if name != nameOfE {
+		return name
+	}
+	h(&Context{handlerName: &name})
+	return name
+}
+
+// ErrorHandler turns an error returned by a handler into a response.
+type ErrorHandler func(*Context, error)
+
+// HTTPError is an error carrying the HTTP status, the application code and
+// the message of the response written by DefaultErrorHandler.
+type HTTPError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Err is the underlying error, it is not sent to the client.
+	Err error `json:"-"`
+}
+
+// NewHTTPError returns an HTTPError, the message defaults to the status text.
+func NewHTTPError(status, code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	msg := "code=" + strconv.Itoa(e.Code) + ", message=" + e.Message
+	if e.Err != nil {
+		msg += ", err=" + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap sets the underlying error and returns e.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// DefaultErrorHandler writes an HTTPError with its status, as JSON if the client accepts it,
+// and the errors of the binding package as problem details, see BindingProblem.
+// Any other error is logged and answered with a 500.
+func DefaultErrorHandler(c *Context, err error) {
+	if isBindingError(err) {
+		c.AbortWithProblem(BindingProblem(err))
+		return
+	}
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		c.engine.opts.Log.Error("plum: handler error",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		he = NewHTTPError(http.StatusInternalServerError, http.StatusInternalServerError, "")
+	}
+	serveError(c, he)
+}
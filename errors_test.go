@@ -0,0 +1,110 @@
+package plum
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-plum/plum/binding"
+)
+
+func getUser(c *Context) error {
+	return nil
+}
+
+func TestERouteHandlerName(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/users", E(getUser))
+	p.GET("/anonymous", E(func(c *Context) error { return nil }))
+
+	want := map[string]string{
+		"GET /users":     "github.com/go-plum/plum.getUser",
+		"GET /anonymous": "github.com/go-plum/plum.TestERouteHandlerName.func1",
+	}
+	for _, route := range p.Routes() {
+		if route.Handler != want[route.Pattern] {
+			t.Errorf("%s: Handler = %q, want %q", route.Pattern, route.Handler, want[route.Pattern])
+		}
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:   "http error",
+			err:    NewHTTPError(http.StatusConflict, 4091, "already exists"),
+			status: http.StatusConflict, contentType: "text/plain; charset=utf-8", body: "already exists",
+		},
+		{
+			name:   "http error as json",
+			err:    NewHTTPError(http.StatusConflict, 4091, "already exists"),
+			accept: "application/json",
+			status: http.StatusConflict, contentType: "application/json; charset=utf-8", body: `{"code":4091,"message":"already exists"}`,
+		},
+		{
+			name:   "wrapped http error",
+			err:    fmt.Errorf("create user: %w", NewHTTPError(http.StatusForbidden, 1, "")),
+			accept: "application/vnd.api+json",
+			status: http.StatusForbidden, contentType: "application/json; charset=utf-8", body: `{"code":1,"message":"Forbidden"}`,
+		},
+		{
+			name:   "validation error",
+			err:    binding.ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}},
+			status: http.StatusUnprocessableEntity, contentType: MIMEProblemJSON, body: `"name":"name"`,
+		},
+		{
+			name:   "field error",
+			err:    fmt.Errorf("bind: %w", binding.FieldErrors{{Field: "page", Source: binding.SourceQuery, Reason: "invalid"}}),
+			status: http.StatusBadRequest, contentType: MIMEProblemJSON, body: `"source":"query"`,
+		},
+		{
+			name:   "unknown error",
+			err:    errors.New("database is down"),
+			status: http.StatusInternalServerError, contentType: "text/plain; charset=utf-8", body: "Internal Server Error",
+		},
+		{
+			name:   "unknown error as json",
+			err:    errors.New("database is down"),
+			accept: "application/json",
+			status: http.StatusInternalServerError, contentType: "application/json; charset=utf-8", body: `{"code":500,"message":"Internal Server Error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestEngine()
+			p.GET("/", E(func(c *Context) error {
+				return tt.err
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body = %q, want it to contain %q", w.Body.String(), tt.body)
+			}
+			if strings.Contains(w.Body.String(), "database") {
+				t.Errorf("the error is sent to the client: %q", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package plum
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// mustParsePrefixes parses addresses and CIDR prefixes, it panics if one of them is invalid.
+func mustParsePrefixes(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr := netip.MustParseAddr(cidr)
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+	}
+	return prefixes
+}
+
+func prefixesContain(prefixes []netip.Prefix, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedValues returns the values of the key in the elements of the RFC 7239 Forwarded
+// header lines, from the first proxy to the last one, with an empty string for
+// the elements without the key.
+func forwardedValues(lines []string, key string) []string {
+	var values []string
+	for _, line := range lines {
+		for _, element := range splitQuoted(line, ',') {
+			value := ""
+			for _, pair := range splitQuoted(element, ';') {
+				k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(k, key) {
+					value = strings.Trim(v, `"`)
+					break
+				}
+			}
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// splitQuoted splits s around sep outside of the quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// headerValues returns the comma separated values of the header lines.
+func headerValues(lines []string) []string {
+	var values []string
+	for _, line := range lines {
+		for _, value := range strings.Split(line, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values
+}
+
+// parseNode parses an IP address with an optional port, such as a Forwarded "for" node
+// ("192.0.2.60", "[2001:db8::17]:4711"). It fails on the "unknown" and obfuscated nodes.
+func parseNode(node string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	ip, err := netip.ParseAddr(strings.Trim(node, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
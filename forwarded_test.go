@@ -0,0 +1,60 @@
+package plum
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestForwardedValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		key   string
+		want  []string
+	}{
+		{"single", []string{"for=192.0.2.60;proto=http;by=203.0.113.43"}, "for", []string{"192.0.2.60"}},
+		{"list", []string{"for=192.0.2.43, for=198.51.100.17"}, "for", []string{"192.0.2.43", "198.51.100.17"}},
+		{"lines", []string{"for=192.0.2.43", "for=198.51.100.17"}, "for", []string{"192.0.2.43", "198.51.100.17"}},
+		{"missing key", []string{"for=192.0.2.43;host=a, for=198.51.100.17"}, "host", []string{"a", ""}},
+		{"case and spaces", []string{"For=192.0.2.43 ; PROTO=https"}, "proto", []string{"https"}},
+		{"quoted ipv6", []string{`for="[2001:db8:cafe::17]:4711"`}, "for", []string{"[2001:db8:cafe::17]:4711"}},
+		{"quoted separators", []string{`for=_hidden;host="a,b;c", for=unknown`}, "host", []string{"a,b;c", ""}},
+		{"obfuscated", []string{"for=_hidden, for=unknown"}, "for", []string{"_hidden", "unknown"}},
+	}
+	for _, tt := range tests {
+		if got := forwardedValues(tt.lines, tt.key); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: forwardedValues(%q, %q) = %q, want %q", tt.name, tt.lines, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParseNode(t *testing.T) {
+	tests := []struct {
+		node string
+		want string
+	}{
+		{"192.0.2.60", "192.0.2.60"},
+		{"192.0.2.60:8080", "192.0.2.60"},
+		{"2001:db8::17", "2001:db8::17"},
+		{"[2001:db8::17]", "2001:db8::17"},
+		{"[2001:db8::17]:4711", "2001:db8::17"},
+		{"[::ffff:192.0.2.1]:80", "192.0.2.1"},
+		{"unknown", ""},
+		{"_hidden", ""},
+		{"[2001:db8::17]:_port", "2001:db8::17"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		ip, ok := parseNode(tt.node)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseNode(%q) = %v, want an error", tt.node, ip)
+			}
+			continue
+		}
+		if !ok || ip != netip.MustParseAddr(tt.want) {
+			t.Errorf("parseNode(%q) = %v, %v, want %s", tt.node, ip, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package plum
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+)
+
+// Dir returns an http.FileSystem serving the files under root, see FS.
+func Dir(root string, listDirectory bool) http.FileSystem {
+	return newFileSystem(http.Dir(root), listDirectory)
+}
+
+// FS returns an http.FileSystem serving the files of fsys, such as an embed.FS.
+// Unless listDirectory is true, the directories without an index.html file
+// are not found instead of being listed.
+func FS(fsys fs.FS, listDirectory bool) http.FileSystem {
+	return newFileSystem(http.FS(fsys), listDirectory)
+}
+
+func newFileSystem(fsys http.FileSystem, listDirectory bool) http.FileSystem {
+	if listDirectory {
+		return fsys
+	}
+	return onlyFilesFS{fsys}
+}
+
+// onlyFilesFS is an http.FileSystem hiding the directories without an index.html file.
+type onlyFilesFS struct {
+	fs http.FileSystem
+}
+
+// Open implements the http.FileSystem interface.
+func (o onlyFilesFS) Open(name string) (http.File, error) {
+	f, err := o.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		index, err := o.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, fs.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+// serveFile serves the file name of fsys, unlike Context.FileFromFS an index.html file
+// is not redirected to its directory. It returns false if the file is not found or is a directory.
+func serveFile(c *Context, name string, fsys http.FileSystem) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		return false
+	}
+	http.ServeContent(c.Writer, c.Request, stat.Name(), stat.ModTime(), f)
+	return true
+}
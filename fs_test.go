@@ -0,0 +1,107 @@
+package plum
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// staticFiles is the content of the served directory.
+var staticFiles = map[string]string{
+	"a.txt":              "file a",
+	"list/b.txt":         "file b",
+	"index/index.html":   "index page",
+	"index/c.txt":        "file c",
+	"nested/deep/d.json": `{"d":1}`,
+}
+
+// staticDir writes staticFiles under a "public" directory, next to a secret file.
+func staticDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "public")
+	for name, content := range staticFiles {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func staticMapFS() fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range staticFiles {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestStaticFS(t *testing.T) {
+	sources := map[string]func(t *testing.T, listDirectory bool) http.FileSystem{
+		"Dir": func(t *testing.T, listDirectory bool) http.FileSystem {
+			return Dir(staticDir(t), listDirectory)
+		},
+		"FS": func(t *testing.T, listDirectory bool) http.FileSystem {
+			return FS(staticMapFS(), listDirectory)
+		},
+	}
+	tests := []struct {
+		name          string
+		target        string
+		listDirectory bool
+		status        int
+		body          string
+	}{
+		{"file", "/static/a.txt", false, http.StatusOK, "file a"},
+		{"nested file", "/static/nested/deep/d.json", false, http.StatusOK, `{"d":1}`},
+		{"missing file", "/static/missing.txt", false, http.StatusNotFound, ""},
+		{"index", "/static/index/", false, http.StatusOK, "index page"},
+		{"index with listing", "/static/index/", true, http.StatusOK, "index page"},
+		{"no listing", "/static/list/", false, http.StatusNotFound, ""},
+		{"listing", "/static/list/", true, http.StatusOK, "b.txt"},
+		{"root listing", "/static/", true, http.StatusOK, "a.txt"},
+	}
+	for sourceName, source := range sources {
+		for _, tt := range tests {
+			t.Run(sourceName+"/"+tt.name, func(t *testing.T) {
+				p := newTestEngine()
+				p.StaticFS("/static", source(t, tt.listDirectory))
+
+				w := serve(p, http.MethodGet, tt.target)
+				if w.Code != tt.status {
+					t.Fatalf("status = %d, want %d", w.Code, tt.status)
+				}
+				if !strings.Contains(w.Body.String(), tt.body) {
+					t.Errorf("body = %q, want it to contain %q", w.Body.String(), tt.body)
+				}
+			})
+		}
+	}
+}
+
+func TestStaticTraversal(t *testing.T) {
+	p := newTestEngine()
+	p.Static("/static", staticDir(t))
+
+	for _, target := range []string{
+		"/static/../secret.txt",
+		"/static/%2e%2e/secret.txt",
+		"/static/..%2fsecret.txt",
+		"/static/list/../../secret.txt",
+	} {
+		w := serve(p, http.MethodGet, target)
+		if w.Code == http.StatusOK || strings.Contains(w.Body.String(), "secret") {
+			t.Errorf("%s: %d %q, want the secret file not to be served", target, w.Code, w.Body.String())
+		}
+	}
+}
@@ -6,21 +6,47 @@ import (
 
 type HandlerFunc func(*Context)
 
+// Middleware wraps a HandlerFunc. It is adapted to the handler chain of the Context:
+// calling the wrapped HandlerFunc runs the pending handlers, like Context.Next,
+// and returning without calling it aborts the chain.
 type Middleware func(HandlerFunc) HandlerFunc
 
-type RouterHandler struct {
-	h      HandlerFunc
-	engine *Plum
+// Handler adapts the middleware to a HandlerFunc of a handler chain.
+func (m Middleware) Handler() HandlerFunc {
+	h := m(next)
+	return func(c *Context) {
+		index := c.index
+		h(c)
+		if c.index == index {
+			c.Abort()
+		}
+	}
 }
 
-func (r *RouterHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	ctx := r.engine.pool.Get().(*Context)
-	ctx.Writer = w
-	ctx.Request = req
-	ctx.engine = r.engine
-	ctx.reset()
+// next is the HandlerFunc wrapped by middlewares, it runs the pending handlers.
+func next(c *Context) {
+	c.Next()
+}
+
+func middlewareHandlers(m []Middleware) []HandlerFunc {
+	handlers := make([]HandlerFunc, len(m))
+	for i, middleware := range m {
+		handlers[i] = middleware.Handler()
+	}
+	return handlers
+}
 
-	r.h(ctx)
+type RouterHandler struct {
+	handlers  []HandlerFunc
+	engine    *Plum
+	wildcards []wildcard
+	fullPath  string
+	// router and route are the router and handlers the route was registered with,
+	// nil for the NoRoute and NoMethod handlers.
+	router *Router
+	route  []HandlerFunc
+}
 
-	r.engine.pool.Put(ctx)
+func (r *RouterHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.engine.handleContext(w, req, r, defaultStatus)
 }
@@ -0,0 +1,51 @@
+package plum
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAbort(t *testing.T) {
+	var calls []string
+	handler := func(c *Context) { calls = append(calls, "handler") }
+
+	p := newTestEngine()
+	abort := p.Group("/abort")
+	abort.UseFunc(func(c *Context) {
+		calls = append(calls, "abort")
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+	abort.GET("/x", handler)
+
+	next := p.Group("/next")
+	next.UseFunc(func(c *Context) {
+		calls = append(calls, "before")
+		c.Next()
+		calls = append(calls, "after")
+	})
+	next.GET("/x", handler)
+
+	// skip is a Middleware which does not call the wrapped handler.
+	skip := p.Group("/skip", func(HandlerFunc) HandlerFunc {
+		return func(c *Context) { calls = append(calls, "skip") }
+	})
+	skip.GET("/x", handler)
+
+	tests := []struct {
+		target string
+		status int
+		calls  string
+	}{
+		{"/abort/x", http.StatusUnauthorized, "abort"},
+		{"/next/x", http.StatusOK, "before handler after"},
+		{"/skip/x", http.StatusOK, "skip"},
+	}
+	for _, tt := range tests {
+		calls = nil
+		w := serve(p, http.MethodGet, tt.target)
+		if w.Code != tt.status || strings.Join(calls, " ") != tt.calls {
+			t.Errorf("%s: status %d and calls %q, want %d and %q", tt.target, w.Code, calls, tt.status, tt.calls)
+		}
+	}
+}
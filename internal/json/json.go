@@ -1,6 +1,11 @@
 package json
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-plum/plum/codec"
+)
 
 var (
 	Marshal       = json.Marshal
@@ -8,4 +13,39 @@ var (
 	MarshalIndent = json.MarshalIndent
 	NewDecoder    = json.NewDecoder
 	NewEncoder    = json.NewEncoder
+	Indent        = json.Indent
+)
+
+type (
+	UnmarshalTypeError = json.UnmarshalTypeError
+	SyntaxError        = json.SyntaxError
 )
+
+// Std is the codec of encoding/json.
+var Std codec.Codec = stdCodec{}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (stdCodec) NewEncoder(w io.Writer) codec.Encoder {
+	return json.NewEncoder(w)
+}
+
+func (stdCodec) NewDecoder(r io.Reader) codec.Decoder {
+	return json.NewDecoder(r)
+}
+
+// Codec returns c, or Std if c is nil.
+func Codec(c codec.Codec) codec.Codec {
+	if c == nil {
+		return Std
+	}
+	return c
+}
@@ -1,28 +1,122 @@
 package plum
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"net/http/httputil"
-	"os"
 	"runtime"
+	"strings"
+	"syscall"
 )
 
+// HeaderXRequestID is the header carrying the request id.
+const HeaderXRequestID = "X-Request-Id"
+
+// RecoverOptions configures the middleware returned by RecoverWith.
+type RecoverOptions struct {
+	// Hook is called with the recovered value and the stack, e.g. to report the error.
+	Hook func(ctx *Context, err any, stack []byte)
+	// Response writes the response, a JSON problem details body with status 500 by default.
+	Response func(ctx *Context, err any)
+	// RedactHeaders are the request headers masked in the logged request dump,
+	// "Authorization" and "Cookie" by default.
+	RedactHeaders []string
+	// StackSize is the maximum size of the logged stack, 64 KB by default.
+	StackSize int
+}
+
 // Recover will recover from panics.
 func Recover(handler HandlerFunc) HandlerFunc {
-	return func(ctx *Context) {
-		defer func() {
-			var rawReq []byte
-			if err := recover(); err != nil {
-				buf := make([]byte, 64<<10)
-				buf = buf[:runtime.Stack(buf, false)]
+	return RecoverWith(RecoverOptions{})(handler)
+}
+
+// RecoverWith returns a middleware that recovers from panics, logs them through the
+// engine's Logger and writes a 500 response. The http.ErrAbortHandler panic is repanicked
+// so that the server aborts the connection, and panics caused by a client that went away
+// are logged without writing a response.
+func RecoverWith(opts RecoverOptions) Middleware {
+	if opts.Response == nil {
+		opts.Response = defaultRecoverResponse
+	}
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = []string{"Authorization", "Cookie"}
+	}
+	if opts.StackSize <= 0 {
+		opts.StackSize = 64 << 10
+	}
+
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				stack := make([]byte, opts.StackSize)
+				stack = stack[:runtime.Stack(stack, false)]
+
+				log := ctx.engine.opts.Log
+				attrs := []any{"panic", err}
 				if ctx.Request != nil {
-					rawReq, _ = httputil.DumpRequest(ctx.Request, false)
+					attrs = append(attrs,
+						"method", ctx.Request.Method,
+						"path", ctx.Request.URL.Path,
+						"request_id", ctx.requestHeader(HeaderXRequestID),
+						"request", dumpRequest(ctx.Request, opts.RedactHeaders),
+					)
 				}
-				_, _ = fmt.Fprintf(os.Stderr, "Plum call recovery panic: %s\n%v\n%s\n", string(rawReq), err, buf)
-				ctx.AbortWithStatus(http.StatusInternalServerError)
-			}
-		}()
-		handler(ctx)
+				attrs = append(attrs, "stack", string(stack))
+
+				if isBrokenPipe(err) {
+					log.Warn("plum: connection closed by client", attrs...)
+					ctx.Abort()
+					return
+				}
+				if opts.Hook != nil {
+					opts.Hook(ctx, err, stack)
+				}
+				log.Error("plum: panic recovered", attrs...)
+				opts.Response(ctx, err)
+				ctx.Abort()
+			}()
+			handler(ctx)
+		}
+	}
+}
+
+func defaultRecoverResponse(ctx *Context, _ any) {
+	if ctx.Writer.Written() {
+		return
+	}
+	ctx.AbortWithProblem(NewProblem(http.StatusInternalServerError, ""))
+}
+
+// dumpRequest dumps the request headers with the values of the redacted ones masked.
+func dumpRequest(req *http.Request, redacted []string) string {
+	r := new(http.Request)
+	*r = *req
+	r.Header = req.Header.Clone()
+	for _, key := range redacted {
+		if r.Header.Get(key) != "" {
+			r.Header.Set(key, "[REDACTED]")
+		}
+	}
+	raw, _ := httputil.DumpRequest(r, false)
+	return string(raw)
+}
+
+// isBrokenPipe reports whether err is caused by a connection closed by the client.
+func isBrokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET) {
+		return true
 	}
+	msg := strings.ToLower(e.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
 }
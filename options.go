@@ -2,9 +2,11 @@ package plum
 
 import (
 	"log/slog"
+	"net/netip"
 	"os"
 	"time"
 
+	"github.com/go-plum/plum/codec"
 	"github.com/go-plum/plum/render"
 )
 
@@ -13,8 +15,18 @@ type serverOptions struct {
 
 	MaxMultipartMemory int64
 	readHeaderTimeout  time.Duration
+	printRoutes        bool
+	bufferedRender     bool
 
-	HTMLRender render.HTMLRender
+	HTMLRender       render.HTMLRender
+	secureJSONPrefix string
+	jsonCodec        codec.Codec
+
+	pprof          *PprofConfig
+	recover        RecoverOptions
+	errorHandler   ErrorHandler
+	trustedProxies []netip.Prefix
+	proxyProtocol  *proxyListener
 }
 
 const defaultMultipartMemory = 32 << 20 // 32 MB
@@ -23,6 +35,9 @@ var defaultServerOptions = serverOptions{
 	Log:                slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
 	MaxMultipartMemory: defaultMultipartMemory,
 	readHeaderTimeout:  time.Second * 45,
+	printRoutes:        true,
+	errorHandler:       DefaultErrorHandler,
+	secureJSONPrefix:   "while(1);",
 }
 
 // A ServerOption sets options such as credentials, codec and keepalive parameters, etc.
@@ -66,3 +81,86 @@ func WithLogger(log Logger) ServerOption {
 		o.Log = log
 	})
 }
+
+// PrintRoutes enables or disables logging every registered route at debug level.
+func PrintRoutes(enabled bool) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.printRoutes = enabled
+	})
+}
+
+// PprofConfig configures the net/http/pprof routes, which are not mounted by default.
+type PprofConfig struct {
+	// Prefix is the path prefix of the profiling routes, DefaultPrefix if empty.
+	Prefix string
+	// Addr, if not empty, serves the profiling routes on a dedicated listener
+	// started by Run, RunTLS and RunServer instead of the public one.
+	Addr string
+	// Middlewares guard the profiling routes, see AllowIPs, BasicAuth and BearerToken.
+	Middlewares []Middleware
+}
+
+// WithPprof mounts the net/http/pprof routes.
+func WithPprof(c PprofConfig) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.pprof = &c
+	})
+}
+
+// WithRecover configures the Recover middleware installed by New.
+func WithRecover(r RecoverOptions) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.recover = r
+	})
+}
+
+// WithErrorHandler sets the handler of the errors passed to Context.Error,
+// DefaultErrorHandler by default.
+func WithErrorHandler(h ErrorHandler) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.errorHandler = h
+	})
+}
+
+// SecureJSONPrefix sets the prefix written by Context.SecureJSON, "while(1);" by default.
+func SecureJSONPrefix(prefix string) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.secureJSONPrefix = prefix
+	})
+}
+
+// WithJSONCodec sets the codec of the JSON bindings and renderers, encoding/json by default.
+func WithJSONCodec(c codec.Codec) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.jsonCodec = c
+	})
+}
+
+// BufferedRender enables rendering the responses into a pooled buffer before writing them,
+// so that a render failure becomes a 500 response and the Content-Length is always set.
+// Redirects and streams, such as Context.DataFromReader, are not buffered.
+func BufferedRender(enabled bool) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.bufferedRender = enabled
+	})
+}
+
+// TrustedProxies sets the addresses and CIDR prefixes of the proxies whose forwarding
+// headers are read by Context.ClientIP, Context.Scheme and Context.Host. No proxy is
+// trusted by default. It panics if one of them is invalid.
+func TrustedProxies(cidrs ...string) ServerOption {
+	prefixes := mustParsePrefixes(cidrs)
+	return newFuncServerOption(func(o *serverOptions) {
+		o.trustedProxies = prefixes
+	})
+}
+
+// WithProxyProtocol makes Run, RunTLS and RunServer read the PROXY protocol headers
+// of the connections, see ProxyListener. It panics if TrustedSources is empty or one of them
+// is invalid.
+func WithProxyProtocol(c ProxyProtocolConfig) ServerOption {
+	l := newProxyListener(c)
+	return newFuncServerOption(func(o *serverOptions) {
+		o.proxyProtocol = l
+	})
+}
@@ -1,6 +1,10 @@
 package plum
 
-import "path"
+import (
+	"net/url"
+	"path"
+	"strings"
+)
 
 func joinPaths(absolutePath, relativePath string) string {
 	if relativePath == "" {
@@ -20,3 +24,55 @@ func lastChar(str string) uint8 {
 	}
 	return str[len(str)-1]
 }
+
+// wildcard is a named segment of a route pattern, such as {id} or {rest...}.
+type wildcard struct {
+	index int
+	name  string
+	multi bool
+}
+
+// parseWildcards returns the wildcards of a ServeMux pattern
+// ("[METHOD ][HOST]/[PATH]") in the order they appear in the path.
+func parseWildcards(pattern string) []wildcard {
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		pattern = strings.TrimLeft(pattern[i:], " \t")
+	}
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	}
+
+	var ws []wildcard
+	for i, seg := range strings.Split(strings.TrimPrefix(pattern, "/"), "/") {
+		if len(seg) < 2 || seg[0] != '{' || seg[len(seg)-1] != '}' || seg == "{$}" {
+			continue
+		}
+		name, multi := strings.CutSuffix(seg[1:len(seg)-1], "...")
+		ws = append(ws, wildcard{index: i, name: name, multi: multi})
+	}
+	return ws
+}
+
+// matchWildcards appends the values captured by ws from the escaped request path to ps.
+// The path is expected to be the one the ServeMux matched, so it is already clean.
+func matchWildcards(ws []wildcard, path string, ps Params) Params {
+	if len(ws) == 0 {
+		return ps
+	}
+	segs := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for _, w := range ws {
+		if w.index >= len(segs) {
+			break
+		}
+		raw := segs[w.index]
+		if w.multi {
+			raw = strings.Join(segs[w.index:], "/")
+		}
+		value, err := url.PathUnescape(raw)
+		if err != nil {
+			value = raw
+		}
+		ps = append(ps, Param{Key: w.name, Value: value})
+	}
+	return ps
+}
@@ -3,12 +3,26 @@ package plum
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
+
+	"github.com/go-plum/plum/binding"
+)
+
+const (
+	default404Body = "404 page not found"
+	default405Body = "405 method not allowed"
 )
 
+// anyMethods are the methods registered by Router.Any and probed for the Allow header of a 405 response.
+var anyMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
 type Plum struct {
 	Router
 	opts serverOptions
@@ -16,6 +30,19 @@ type Plum struct {
 	mux  *http.ServeMux
 	srv  *http.Server
 
+	// admin serves the profiling routes when PprofConfig.Addr is set.
+	admin *http.Server
+
+	routes []RouteInfo
+	// handlers are the handlers of the routes, in the order of routes.
+	handlers []*RouterHandler
+	noRoute  []HandlerFunc
+	noMethod []HandlerFunc
+	// globals are the handlers run before the route lookup, followed by dispatch.
+	globals []HandlerFunc
+
+	// RemoteIPHeaders are the headers read by Context.ClientIP, in order, when the request
+	// comes from one of the TrustedProxies. A "Forwarded" header is parsed as RFC 7239.
 	RemoteIPHeaders []string
 }
 
@@ -30,17 +57,30 @@ func New(opt ...ServerOption) *Plum {
 		Router: Router{
 			basePath: "/",
 		},
-		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		RemoteIPHeaders: []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"},
 		mux:             http.NewServeMux(),
 	}
-	p.Use(Recover)
+	p.globals = []HandlerFunc{p.dispatch}
+	p.UseGlobal(RecoverWith(opts.recover))
 
 	p.pool.New = func() any {
 		return p.allocateContext()
 	}
 	p.Router.engine = p
 
-	RoutePerf(&p.Router)
+	if pc := opts.pprof; pc != nil {
+		if pc.Addr == "" {
+			RoutePerfWith(&p.Router, pc.Prefix, pc.Middlewares...)
+		} else {
+			admin := New(WithLogger(opts.Log), PrintRoutes(opts.printRoutes))
+			RoutePerfWith(&admin.Router, pc.Prefix, pc.Middlewares...)
+			p.admin = &http.Server{
+				Handler:           admin,
+				Addr:              pc.Addr,
+				ReadHeaderTimeout: opts.readHeaderTimeout,
+			}
+		}
+	}
 	return p
 }
 
@@ -53,7 +93,15 @@ func (p *Plum) Run(addr string, server ...*http.Server) error {
 	if len(server) != 0 {
 		p.srv = server[0]
 	}
-	return p.srv.ListenAndServe()
+	p.startAdmin()
+	if p.opts.proxyProtocol == nil {
+		return p.srv.ListenAndServe()
+	}
+	lis, err := p.listen(":http")
+	if err != nil {
+		return err
+	}
+	return p.srv.Serve(lis)
 }
 
 func (p *Plum) RunTLS(addr, certFile, keyFile string, server ...*http.Server) error {
@@ -65,7 +113,15 @@ func (p *Plum) RunTLS(addr, certFile, keyFile string, server ...*http.Server) er
 	if len(server) != 0 {
 		p.srv = server[0]
 	}
-	return p.srv.ListenAndServeTLS(certFile, keyFile)
+	p.startAdmin()
+	if p.opts.proxyProtocol == nil {
+		return p.srv.ListenAndServeTLS(certFile, keyFile)
+	}
+	lis, err := p.listen(":https")
+	if err != nil {
+		return err
+	}
+	return p.srv.ServeTLS(lis, certFile, keyFile)
 }
 
 func (p *Plum) RunServer(lis net.Listener, server *http.Server) error {
@@ -74,14 +130,62 @@ func (p *Plum) RunServer(lis net.Listener, server *http.Server) error {
 	}
 	server.Handler = p
 	p.srv = server
+	p.startAdmin()
+	if p.opts.proxyProtocol != nil {
+		lis = p.wrapProxyProtocol(lis)
+	}
 	return p.srv.Serve(lis)
 }
 
+// listen listens on the address of the server, or defaultAddr if empty,
+// reading the PROXY protocol headers.
+func (p *Plum) listen(defaultAddr string) (net.Listener, error) {
+	addr := p.srv.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return p.wrapProxyProtocol(lis), nil
+}
+
+// wrapProxyProtocol wraps the listener in a ProxyListener and makes the headers
+// available to Context.ProxyHeader.
+func (p *Plum) wrapProxyProtocol(lis net.Listener) net.Listener {
+	connContext := p.srv.ConnContext
+	p.srv.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+		if connContext != nil {
+			ctx = connContext(ctx, conn)
+		}
+		return proxyConnContext(ctx, conn)
+	}
+	return p.opts.proxyProtocol.wrap(lis)
+}
+
+// startAdmin serves the profiling routes on their dedicated listener, if any.
+func (p *Plum) startAdmin() {
+	if p.admin == nil {
+		return
+	}
+	go func() {
+		if err := p.admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.opts.Log.Error("plum: pprof server stopped", "addr", p.admin.Addr, "error", err)
+		}
+	}()
+}
+
 // Shutdown the http server without interrupting active connections.
 func (p *Plum) Shutdown(ctx context.Context) error {
 	if p.srv == nil {
 		return errors.New("plum: no server")
 	}
+	if p.admin != nil {
+		if err := p.admin.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
 	return p.srv.Shutdown(ctx)
 }
 
@@ -95,12 +199,169 @@ func (p *Plum) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	ctx := p.pool.Get().(*Context)
+	ctx.writermem.reset(res)
+	ctx.Request = req
+	ctx.engine = p
+	ctx.reset()
+
+	ctx.handlers = p.globals
+	ctx.Next()
+	ctx.writermem.WriteHeaderNow()
+
+	p.pool.Put(ctx)
+}
+
+// UseGlobal adds middlewares run for every request before the route lookup, including
+// the requests that match no route. They may rewrite Context.Request, such as its URL
+// path or method, to change the route the request is dispatched to.
+func (p *Plum) UseGlobal(m ...Middleware) {
+	p.UseGlobalFunc(middlewareHandlers(m)...)
+}
+
+// UseGlobalFunc adds gin-style middlewares run for every request before the route lookup,
+// see UseGlobal.
+func (p *Plum) UseGlobalFunc(handlers ...HandlerFunc) {
+	if len(p.globals)+len(handlers) >= int(abortIndex) {
+		panic("plum: too many handlers")
+	}
+	last := len(p.globals) - 1
+	p.globals = slices.Concat(p.globals[:last], handlers, p.globals[last:])
+}
+
+// dispatch looks Context.Request up in the mux and runs the handler chain of its route,
+// or the NoRoute or NoMethod handlers, as the last handler of the global chain.
+func (p *Plum) dispatch(c *Context) {
+	req := c.Request
 	h, pt := p.mux.Handler(req)
-	if pt == "" {
-		fmt.Println("not found ") // TODO NOT FOUND
+	var rh *RouterHandler
+	switch {
+	case pt == "":
+		if allowed := p.allowedMethods(req); len(allowed) > 0 {
+			c.Header("Allow", strings.Join(allowed, ", "))
+			rh = p.fallback(p.noMethod)
+			c.writermem.status = http.StatusMethodNotAllowed
+		} else {
+			rh = p.fallback(p.noRoute)
+			c.writermem.status = http.StatusNotFound
+		}
+	default:
+		var ok bool
+		if rh, ok = h.(*RouterHandler); !ok {
+			h.ServeHTTP(c.Writer, req)
+			return
+		}
+	}
+	p.runRoute(c, rh)
+}
+
+// Routes returns the registered routes in registration order.
+func (p *Plum) Routes() []RouteInfo {
+	return slices.Clone(p.routes)
+}
+
+// NoRoute adds handlers for requests that match no route.
+// The handlers run behind the middlewares of the engine with the status set to 404;
+// the default body is written if none of them writes the response.
+func (p *Plum) NoRoute(handlers ...HandlerFunc) {
+	p.noRoute = handlers
+}
+
+// NoMethod adds handlers for requests whose path matches a route registered for other methods.
+// The Allow header and the 405 status are already set when they run;
+// the default body is written if none of them writes the response.
+func (p *Plum) NoMethod(handlers ...HandlerFunc) {
+	p.noMethod = handlers
+}
+
+// rebuildRoutes rebuilds the handler chains of the routes registered on the router
+// and its nested groups, after middlewares are added to it.
+func (p *Plum) rebuildRoutes(r *Router) {
+	for i, rh := range p.handlers {
+		if r.contains(rh.router) {
+			rh.handlers = rh.router.combineHandlers(rh.route)
+			p.routes[i].Middlewares = len(rh.handlers) - 1
+		}
+	}
+}
+
+// fallback composes the NoRoute or NoMethod handlers with the middlewares of the engine.
+func (p *Plum) fallback(handlers []HandlerFunc) *RouterHandler {
+	return &RouterHandler{
+		engine:   p,
+		handlers: slices.Concat(p.middlewares, handlers, []HandlerFunc{defaultFallback}),
+	}
+}
+
+// allowedMethods returns the methods whose routes match the path of req.
+func (p *Plum) allowedMethods(req *http.Request) []string {
+	var allowed []string
+	probe := new(http.Request)
+	*probe = *req
+	for _, method := range anyMethods {
+		if method == req.Method {
+			continue
+		}
+		probe.Method = method
+		h, _ := p.mux.Handler(probe)
+		if _, ok := h.(*RouterHandler); ok {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// defaultFallback writes the default 404 or 405 body, unless the response is written
+// or its status changed by the NoRoute or NoMethod handlers.
+func defaultFallback(c *Context) {
+	if c.Writer.Written() {
+		return
+	}
+	switch code := c.Writer.Status(); code {
+	case http.StatusNotFound:
+		serveError(c, NewHTTPError(code, code, default404Body))
+	case http.StatusMethodNotAllowed:
+		serveError(c, NewHTTPError(code, code, default405Body))
+	}
+}
+
+// serveError writes a JSON body if the client accepts it, a plain text body otherwise.
+func serveError(c *Context, he *HTTPError) {
+	accept := c.requestHeader("Accept")
+	if strings.Contains(accept, binding.MIMEJSON) || strings.Contains(accept, "+json") {
+		c.JSON(he.Status, he)
 		return
 	}
-	h.ServeHTTP(res, req)
+	c.String(he.Status, he.Message)
+}
+
+// handleContext runs the handler chain of rh with a pooled Context for the request,
+// the response status defaults to status.
+func (p *Plum) handleContext(w http.ResponseWriter, req *http.Request, rh *RouterHandler, status int) {
+	ctx := p.pool.Get().(*Context)
+	ctx.writermem.reset(w)
+	ctx.writermem.status = status
+	ctx.Request = req
+	ctx.engine = p
+	ctx.reset()
+
+	p.runRoute(ctx, rh)
+	ctx.writermem.WriteHeaderNow()
+
+	p.pool.Put(ctx)
+}
+
+// runRoute sets the route of the context and runs its handler chain.
+func (p *Plum) runRoute(c *Context, rh *RouterHandler) {
+	c.fullPath = rh.fullPath
+	c.Params = matchWildcards(rh.wildcards, c.Request.URL.EscapedPath(), c.Params[:0])
+	for _, param := range c.Params {
+		c.Request.SetPathValue(param.Key, param.Value)
+	}
+
+	c.handlers = rh.handlers
+	c.index = -1
+	c.Next()
 }
 
 func (p *Plum) allocateContext() *Context {
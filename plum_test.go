@@ -0,0 +1,144 @@
+package plum
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestEngine returns an engine which does not log.
+func newTestEngine() *Plum {
+	return New(WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+}
+
+// serve serves a request without body and returns the response.
+func serve(p *Plum, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestParams(t *testing.T) {
+	p := newTestEngine()
+	var params Params
+	var pathValue string
+	p.GET("/users/{id}/files/{path...}", func(c *Context) {
+		params = append(Params(nil), c.Params...)
+		pathValue = c.Request.PathValue("id")
+	})
+
+	if w := serve(p, http.MethodGet, "/users/42/files/a/b%20c.txt"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := params.ByName("id"); got != "42" {
+		t.Errorf("id = %q, want %q", got, "42")
+	}
+	if got := params.ByName("path"); got != "a/b c.txt" {
+		t.Errorf("path = %q, want %q", got, "a/b c.txt")
+	}
+	if pathValue != "42" {
+		t.Errorf("PathValue(id) = %q, want %q", pathValue, "42")
+	}
+}
+
+func TestNoRouteAndNoMethod(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/users", func(c *Context) {})
+	p.POST("/users", func(c *Context) {})
+
+	w := serve(p, http.MethodDelete, "/users")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if got, want := w.Header().Get("Allow"), "GET, HEAD, POST"; got != want {
+		t.Errorf("Allow = %q, want %q", got, want)
+	}
+
+	if w := serve(p, http.MethodGet, "/nope"); w.Code != http.StatusNotFound || w.Header().Get("Allow") != "" {
+		t.Errorf("status = %d with Allow %q, want %d without Allow", w.Code, w.Header().Get("Allow"), http.StatusNotFound)
+	}
+
+	p.NoRoute(func(c *Context) { c.String(http.StatusTeapot, "custom") })
+	p.NoMethod(func(c *Context) { c.String(http.StatusMethodNotAllowed, "no method") })
+	if w := serve(p, http.MethodGet, "/nope"); w.Code != http.StatusTeapot || w.Body.String() != "custom" {
+		t.Errorf("NoRoute: got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(p, http.MethodDelete, "/users"); w.Code != http.StatusMethodNotAllowed || w.Body.String() != "no method" {
+		t.Errorf("NoMethod: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestHeadPrecedence(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/implicit", func(c *Context) { c.Header("X-Route", "get") })
+	p.GET("/explicit", func(c *Context) { c.Header("X-Route", "get") })
+	p.HEAD("/explicit", func(c *Context) { c.Header("X-Route", "head") })
+	p.Any("/any", func(c *Context) { c.Header("X-Route", c.Request.Method) })
+
+	tests := []struct {
+		method, target, want string
+	}{
+		{http.MethodHead, "/implicit", "get"},
+		{http.MethodHead, "/explicit", "head"},
+		{http.MethodGet, "/explicit", "get"},
+		{http.MethodPatch, "/any", http.MethodPatch},
+		{http.MethodTrace, "/any", http.MethodTrace},
+	}
+	for _, tt := range tests {
+		if got := serve(p, tt.method, tt.target).Header().Get("X-Route"); got != tt.want {
+			t.Errorf("%s %s: route %q, want %q", tt.method, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestUseGlobalRewrite(t *testing.T) {
+	p := newTestEngine()
+	p.UseGlobalFunc(func(c *Context) {
+		if v, ok := strings.CutPrefix(c.Request.URL.Path, "/v1"); ok {
+			c.Request.URL.Path = v
+		}
+	})
+	var path string
+	p.GET("/users", func(c *Context) {
+		path = c.FullPath()
+	})
+
+	if w := serve(p, http.MethodGet, "/v1/users"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if path != "/users" {
+		t.Errorf("FullPath() = %q, want %q", path, "/users")
+	}
+}
+
+func TestUseGlobalUnmatched(t *testing.T) {
+	p := newTestEngine()
+	var ran int
+	p.UseGlobalFunc(func(c *Context) {
+		ran++
+		c.Header("X-Global", "1")
+	})
+	p.GET("/users", func(c *Context) {})
+
+	for _, tt := range []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound},
+		{http.MethodPost, "/users", http.StatusMethodNotAllowed},
+	} {
+		w := serve(p, tt.method, tt.target)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, w.Code, tt.status)
+		}
+		if w.Header().Get("X-Global") != "1" {
+			t.Errorf("%s %s: the global middleware did not run", tt.method, tt.target)
+		}
+	}
+	if ran != 2 {
+		t.Errorf("global middleware ran %d times, want 2", ran)
+	}
+}
@@ -19,12 +19,20 @@ func getPrefix(prefixOptions ...string) string {
 }
 
 // RoutePerf the standard HandlerFuncs from the net/http/pprof package with
-// the provided gin.GrouterGroup. prefixOptions is a optional. If not prefixOptions,
+// the provided Router. prefixOptions is a optional. If not prefixOptions,
 // the default path prefix is used, otherwise first prefixOptions will be path prefix.
 func RoutePerf(rg *Router, prefixOptions ...string) {
-	prefix := getPrefix(prefixOptions...)
+	RoutePerfWith(rg, getPrefix(prefixOptions...))
+}
+
+// RoutePerfWith is like RoutePerf, but guards the profiling routes with the given middlewares,
+// see AllowIPs, BasicAuth and BearerToken.
+func RoutePerfWith(rg *Router, prefix string, m ...Middleware) {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
 
-	prefixRouter := rg.Group(prefix)
+	prefixRouter := rg.Group(prefix, m...)
 	{
 		prefixRouter.GET("/", pprofHandlerFunc(pprof.Index))
 		prefixRouter.GET("/cmdline", pprofHandlerFunc(pprof.Cmdline))
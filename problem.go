@@ -0,0 +1,100 @@
+package plum
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-plum/plum/binding"
+)
+
+// MIMEProblemJSON is the media type of a problem details body.
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// InvalidParams is the "invalid-params" extension listing the request
+	// values that cannot be bound or do not pass the validation.
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam is a member of the "invalid-params" extension of a Problem.
+type InvalidParam struct {
+	// Name is the path of the field, see binding.FieldError and binding.ValidationError.
+	Name string `json:"name"`
+	// Source is one of binding.SourceBody, SourceQuery, SourceURI and SourceHeader.
+	Source string `json:"source,omitempty"`
+	// Expected is the Go type of the field.
+	Expected string `json:"expected,omitempty"`
+	// Rule is the failed rule of the `validate` tag.
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// NewProblem returns a problem of type "about:blank" titled after the status code.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// BindingProblem returns the problem describing an error of the binding package:
+// a 422 for binding.ValidationErrors, a 400 otherwise, with the invalid params
+// of binding.FieldErrors and binding.ValidationErrors.
+func BindingProblem(err error) *Problem {
+	var ves binding.ValidationErrors
+	if errors.As(err, &ves) {
+		p := NewProblem(http.StatusUnprocessableEntity, "The request did not pass the validation.")
+		for _, ve := range ves {
+			p.InvalidParams = append(p.InvalidParams, InvalidParam{
+				Name:   ve.Field,
+				Rule:   ve.Rule,
+				Reason: ve.Message,
+			})
+		}
+		return p
+	}
+
+	var fes binding.FieldErrors
+	if !errors.As(err, &fes) {
+		var fe *binding.FieldError
+		if !errors.As(err, &fe) {
+			return NewProblem(http.StatusBadRequest, err.Error())
+		}
+		fes = binding.FieldErrors{fe}
+	}
+	p := NewProblem(http.StatusBadRequest, "The request contains invalid values.")
+	for _, fe := range fes {
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{
+			Name:     fe.Field,
+			Source:   fe.Source,
+			Expected: fe.Expected,
+			Reason:   fe.Reason,
+		})
+	}
+	return p
+}
+
+// isBindingError reports whether err comes from the binding package.
+func isBindingError(err error) bool {
+	var (
+		ves binding.ValidationErrors
+		fes binding.FieldErrors
+		fe  *binding.FieldError
+	)
+	return errors.As(err, &ves) || errors.As(err, &fes) || errors.As(err, &fe)
+}
+
+// AbortWithProblem calls `Abort()` and writes the problem as an "application/problem+json" body.
+func (c *Context) AbortWithProblem(p *Problem) {
+	c.Abort()
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.JSON(p.Status, p)
+}
@@ -0,0 +1,285 @@
+package plum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyV2Signature starts the PROXY protocol version 2 binary header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// proxyV1MaxLength is the maximum length of a PROXY protocol version 1 line, CRLF included.
+	proxyV1MaxLength = 107
+
+	defaultProxyHeaderTimeout = 10 * time.Second
+)
+
+// ErrProxyHeader is returned by the connections of a ProxyListener whose PROXY protocol
+// header is missing or invalid.
+var ErrProxyHeader = errors.New("plum: invalid PROXY protocol header")
+
+// ProxyHeader is a PROXY protocol header sent by a load balancer.
+type ProxyHeader struct {
+	// Version is 1 for the text header and 2 for the binary one.
+	Version int
+	// Source and Destination are the addresses of the connection accepted by the load balancer.
+	// They are nil for the health checks of the load balancer (v2 LOCAL command)
+	// and for the unknown protocols, and the addresses of the connection are used instead.
+	Source      net.Addr
+	Destination net.Addr
+}
+
+// ProxyProtocolConfig configures the PROXY protocol listener.
+type ProxyProtocolConfig struct {
+	// TrustedSources are the addresses and CIDR prefixes of the load balancers, which must
+	// send a PROXY protocol header. The connections from the other sources are served as is.
+	// It must not be empty, "0.0.0.0/0" and "::/0" trust all the sources.
+	TrustedSources []string
+	// HeaderTimeout is the maximum duration to read the header, 10 seconds if zero.
+	HeaderTimeout time.Duration
+}
+
+// ProxyListener wraps a net.Listener to read the PROXY protocol version 1 and 2 headers
+// of the accepted connections, whose RemoteAddr and LocalAddr then return the addresses
+// of the original connection. The header is read on the first call to Read, RemoteAddr
+// or LocalAddr so that Accept never blocks. It panics if TrustedSources is empty or
+// one of them is invalid.
+func ProxyListener(lis net.Listener, cfg ProxyProtocolConfig) net.Listener {
+	return newProxyListener(cfg).wrap(lis)
+}
+
+type proxyListener struct {
+	net.Listener
+	trusted []netip.Prefix
+	timeout time.Duration
+}
+
+func newProxyListener(cfg ProxyProtocolConfig) *proxyListener {
+	if len(cfg.TrustedSources) == 0 {
+		panic("plum: no trusted source for the PROXY protocol")
+	}
+	l := &proxyListener{
+		trusted: mustParsePrefixes(cfg.TrustedSources),
+		timeout: cfg.HeaderTimeout,
+	}
+	if l.timeout == 0 {
+		l.timeout = defaultProxyHeaderTimeout
+	}
+	return l
+}
+
+func (l *proxyListener) wrap(lis net.Listener) net.Listener {
+	return &proxyListener{Listener: lis, trusted: l.trusted, timeout: l.timeout}
+}
+
+// Accept implements the net.Listener interface.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	ip, ok := parseNode(conn.RemoteAddr().String())
+	if !ok || !prefixesContain(l.trusted, ip) {
+		return conn, nil
+	}
+	return &proxyConn{Conn: conn, r: bufio.NewReader(conn), timeout: l.timeout}, nil
+}
+
+// proxyConn is a connection starting with a PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	header *ProxyHeader
+	err    error
+}
+
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			c.err = err
+			return
+		}
+		c.header, c.err = readProxyHeader(c.r)
+		if err := c.Conn.SetReadDeadline(time.Time{}); err != nil && c.err == nil {
+			c.err = err
+		}
+	})
+}
+
+// Read implements the net.Conn interface.
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr implements the net.Conn interface.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && c.header.Source != nil {
+		return c.header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr implements the net.Conn interface.
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && c.header.Destination != nil {
+		return c.header.Destination
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader reads a version 1 or 2 header.
+func readProxyHeader(r *bufio.Reader) (*ProxyHeader, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	switch b[0] {
+	case 'P':
+		return readProxyV1(r)
+	case proxyV2Signature[0]:
+		return readProxyV2(r)
+	}
+	return nil, ErrProxyHeader
+}
+
+// readProxyV1 reads a header such as "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+func readProxyV1(r *bufio.Reader) (*ProxyHeader, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == proxyV1MaxLength {
+			return nil, ErrProxyHeader
+		}
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, c)
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, ErrProxyHeader
+	}
+	header := &ProxyHeader{Version: 1}
+	switch fields[1] {
+	case "UNKNOWN":
+		return header, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, ErrProxyHeader
+	}
+	if len(fields) != 6 {
+		return nil, ErrProxyHeader
+	}
+	src, err := parseProxyV1Addr(fields[2], fields[4], fields[1] == "TCP6")
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseProxyV1Addr(fields[3], fields[5], fields[1] == "TCP6")
+	if err != nil {
+		return nil, err
+	}
+	header.Source, header.Destination = src, dst
+	return header, nil
+}
+
+func parseProxyV1Addr(ip, port string, ipv6 bool) (net.Addr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Is6() != ipv6 {
+		return nil, ErrProxyHeader
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, ErrProxyHeader
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+// readProxyV2 reads a binary header: the signature, the version and command, the family
+// and protocol, the length of the addresses, the addresses and the TLVs, which are ignored.
+func readProxyV2(r *bufio.Reader) (*ProxyHeader, error) {
+	var fixed [16]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fixed[:12], proxyV2Signature) || fixed[12]>>4 != 2 {
+		return nil, ErrProxyHeader
+	}
+	body := make([]byte, binary.BigEndian.Uint16(fixed[14:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	header := &ProxyHeader{Version: 2}
+	switch fixed[12] & 0x0f {
+	case 0x0: // LOCAL
+		return header, nil
+	case 0x1: // PROXY
+	default:
+		return nil, ErrProxyHeader
+	}
+
+	var size int
+	switch fixed[13] >> 4 {
+	case 0x1: // AF_INET
+		size = net.IPv4len
+	case 0x2: // AF_INET6
+		size = net.IPv6len
+	default: // AF_UNSPEC, AF_UNIX
+		return header, nil
+	}
+	if len(body) < 2*size+4 {
+		return nil, ErrProxyHeader
+	}
+	src, _ := netip.AddrFromSlice(body[:size])
+	dst, _ := netip.AddrFromSlice(body[size : 2*size])
+	ports := body[2*size:]
+	srcPort, dstPort := binary.BigEndian.Uint16(ports), binary.BigEndian.Uint16(ports[2:])
+
+	switch fixed[13] & 0x0f {
+	case 0x1: // STREAM
+		header.Source = net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, srcPort))
+		header.Destination = net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, dstPort))
+	case 0x2: // DGRAM
+		header.Source = net.UDPAddrFromAddrPort(netip.AddrPortFrom(src, srcPort))
+		header.Destination = net.UDPAddrFromAddrPort(netip.AddrPortFrom(dst, dstPort))
+	default:
+		return nil, ErrProxyHeader
+	}
+	return header, nil
+}
+
+type proxyConnKey struct{}
+
+// proxyConnContext stores the connection in its context for Context.ProxyHeader.
+// The header is not read here, which would block the accept loop of the server.
+func proxyConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if tc, ok := conn.(*tls.Conn); ok {
+		conn = tc.NetConn()
+	}
+	if pc, ok := conn.(*proxyConn); ok {
+		return context.WithValue(ctx, proxyConnKey{}, pc)
+	}
+	return ctx
+}
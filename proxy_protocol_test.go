@@ -0,0 +1,162 @@
+package plum
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type proxyResult struct {
+	RemoteIP string
+	ClientIP string
+	Version  int
+}
+
+func proxyHandler(c *Context) {
+	res := proxyResult{RemoteIP: c.RemoteIP(), ClientIP: c.ClientIP()}
+	if h := c.ProxyHeader(); h != nil {
+		res.Version = h.Version
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// proxyRequest sends header and a GET request on a new connection to addr.
+func proxyRequest(t *testing.T, addr string, header []byte) (proxyResult, error) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write(header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return proxyResult{}, err
+	}
+	defer resp.Body.Close()
+	var res proxyResult
+	if resp.StatusCode != http.StatusOK {
+		return res, io.ErrUnexpectedEOF
+	}
+	return res, json.NewDecoder(resp.Body).Decode(&res)
+}
+
+func proxyV2Header(local bool) []byte {
+	h := append([]byte{}, proxyV2Signature...)
+	if local {
+		return append(h, 0x20, 0x00, 0, 0)
+	}
+	h = append(h, 0x21, 0x11, 0, 12+3)
+	h = append(h, 198, 51, 100, 9, 10, 0, 0, 1)
+	h = binary.BigEndian.AppendUint16(h, 4242)
+	h = binary.BigEndian.AppendUint16(h, 443)
+	return append(h, 0x01, 0, 0) // empty TLV
+}
+
+func serveProxyProtocol(t *testing.T, trusted ...string) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(WithProxyProtocol(ProxyProtocolConfig{TrustedSources: trusted}))
+	p.GET("/", proxyHandler)
+	go p.RunServer(lis, &http.Server{})
+	t.Cleanup(func() { p.Shutdown(context.Background()) })
+	return lis.Addr().String()
+}
+
+func TestProxyProtocol(t *testing.T) {
+	addr := serveProxyProtocol(t, "127.0.0.1")
+	tests := []struct {
+		name    string
+		header  []byte
+		want    proxyResult
+		wantErr bool
+	}{
+		{"v1 tcp4", []byte("PROXY TCP4 203.0.113.7 10.0.0.1 5555 80\r\n"), proxyResult{"203.0.113.7", "203.0.113.7", 1}, false},
+		{"v1 tcp6", []byte("PROXY TCP6 2001:db8::1 2001:db8::2 5555 80\r\n"), proxyResult{"2001:db8::1", "2001:db8::1", 1}, false},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\n"), proxyResult{"127.0.0.1", "127.0.0.1", 1}, false},
+		{"v2 tcp4", proxyV2Header(false), proxyResult{"198.51.100.9", "198.51.100.9", 2}, false},
+		{"v2 local", proxyV2Header(true), proxyResult{"127.0.0.1", "127.0.0.1", 2}, false},
+		{"missing", nil, proxyResult{}, true},
+		{"invalid v1", []byte("PROXY TCP4 nope 10.0.0.1 5555 80\r\n"), proxyResult{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := proxyRequest(t, addr, tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolUntrustedSource(t *testing.T) {
+	addr := serveProxyProtocol(t, "192.0.2.0/24")
+	// The header of an untrusted source is not read, so the request is invalid.
+	if _, err := proxyRequest(t, addr, []byte("PROXY TCP4 203.0.113.7 10.0.0.1 5555 80\r\n")); err == nil {
+		t.Error("the header of an untrusted source is accepted")
+	}
+	got, err := proxyRequest(t, addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (proxyResult{"127.0.0.1", "127.0.0.1", 0}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestProxyProtocolNoTrustedSource(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic without trusted sources")
+		}
+	}()
+	WithProxyProtocol(ProxyProtocolConfig{})
+}
+
+func TestProxyProtocolTLS(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/", proxyHandler)
+	ts := httptest.NewUnstartedServer(p)
+	ts.Listener = ProxyListener(ts.Listener, ProxyProtocolConfig{TrustedSources: []string{"127.0.0.1"}})
+	ts.Config.ConnContext = proxyConnContext
+	ts.StartTLS()
+	defer ts.Close()
+
+	client := ts.Client()
+	client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err == nil {
+			_, err = conn.Write(proxyV2Header(false))
+		}
+		return conn, err
+	}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got proxyResult
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (proxyResult{"198.51.100.9", "198.51.100.9", 2}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"encoding/csv"
+	"net/http"
+)
+
+// csvFlushRows is the number of rows after which CSV flushes the response.
+const csvFlushRows = 1000
+
+// CSV streams rows as comma-separated values.
+type CSV struct {
+	// Header is the optional first row.
+	Header []string
+	Rows   [][]string
+	// Seq yields the rows written after Rows, its signature is the one of iter.Seq[[]string].
+	Seq func(yield func([]string) bool)
+	// Comma is the field delimiter, ',' by default.
+	Comma rune
+}
+
+var csvContentType = []string{"text/csv; charset=utf-8"}
+
+// Render (CSV) writes the rows with custom ContentType, flushing the response
+// every thousand rows so that large exports are not buffered.
+func (r CSV) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	cw := csv.NewWriter(w)
+	if r.Comma != 0 {
+		cw.Comma = r.Comma
+	}
+
+	n := 0
+	write := func(row []string) error {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		if n++; n%csvFlushRows == 0 {
+			cw.Flush()
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		return cw.Error()
+	}
+
+	if r.Header != nil {
+		if err := write(r.Header); err != nil {
+			return err
+		}
+	}
+	for _, row := range r.Rows {
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	if r.Seq != nil {
+		var err error
+		r.Seq(func(row []string) bool {
+			err = write(row)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteContentType (CSV) writes CSV ContentType.
+func (r CSV) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, csvContentType)
+}
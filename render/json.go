@@ -5,9 +5,13 @@
 package render
 
 import (
+	"bytes"
+	"fmt"
 	"html/template"
 	"net/http"
+	"unicode/utf16"
 
+	"github.com/go-plum/plum/codec"
 	bytesconv "github.com/go-plum/plum/internal/bytes"
 	"github.com/go-plum/plum/internal/json"
 )
@@ -15,22 +19,56 @@ import (
 // JSON contains the given interface object.
 type JSON struct {
 	Data any
+	// Codec marshals Data, encoding/json if nil.
+	Codec codec.Codec
+}
+
+// IndentedJSON contains the given interface object.
+type IndentedJSON struct {
+	Data any
+	// Codec marshals Data, see JSON.
+	Codec codec.Codec
+}
+
+// SecureJSON contains the given interface object and its prefix.
+type SecureJSON struct {
+	Prefix string
+	Data   any
+	// Codec marshals Data, see JSON.
+	Codec codec.Codec
+}
+
+// PureJSON contains the given interface object.
+type PureJSON struct {
+	Data any
+	// Codec marshals Data, see JSON.
+	Codec codec.Codec
+}
+
+// AsciiJSON contains the given interface object.
+type AsciiJSON struct {
+	Data any
+	// Codec marshals Data, see JSON.
+	Codec codec.Codec
 }
 
 // JsonpJSON contains the given interface object its callback.
 type JsonpJSON struct {
 	Callback string
 	Data     any
+	// Codec marshals Data, see JSON.
+	Codec codec.Codec
 }
 
 var (
-	jsonContentType  = []string{"application/json; charset=utf-8"}
-	jsonpContentType = []string{"application/javascript; charset=utf-8"}
+	jsonContentType      = []string{"application/json; charset=utf-8"}
+	jsonpContentType     = []string{"application/javascript; charset=utf-8"}
+	jsonASCIIContentType = []string{"application/json"}
 )
 
 // Render (JSON) writes data with custom ContentType.
 func (r JSON) Render(w http.ResponseWriter) error {
-	return WriteJSON(w, r.Data)
+	return WriteJSONWith(w, r.Codec, r.Data)
 }
 
 // WriteContentType (JSON) writes JSON ContentType.
@@ -38,21 +76,118 @@ func (r JSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, jsonContentType)
 }
 
-// WriteJSON marshals the given interface object and writes it with custom ContentType.
+// WriteJSON marshals the given interface object with encoding/json and writes it
+// with custom ContentType, see WriteJSONWith to use another codec.
 func WriteJSON(w http.ResponseWriter, obj any) error {
+	return WriteJSONWith(w, nil, obj)
+}
+
+// WriteJSONWith marshals the given interface object with the codec, encoding/json
+// if nil, and writes it with custom ContentType.
+func WriteJSONWith(w http.ResponseWriter, c codec.Codec, obj any) error {
+	writeContentType(w, jsonContentType)
+	jsonBytes, err := json.Codec(c).Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// Render (IndentedJSON) marshals the given interface object and writes it with custom ContentType.
+func (r IndentedJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := json.Codec(r.Codec).Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err = json.Indent(&buffer, jsonBytes, "", "    "); err != nil {
+		return err
+	}
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteContentType (IndentedJSON) writes JSON ContentType.
+func (r IndentedJSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, jsonContentType)
-	jsonBytes, err := json.Marshal(obj)
+}
+
+// Render (SecureJSON) marshals the given interface object and writes it with custom ContentType.
+// The prefix is written before the top-level arrays to prevent JSON hijacking.
+func (r SecureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := json.Codec(r.Codec).Marshal(r.Data)
 	if err != nil {
 		return err
 	}
+	if bytes.HasPrefix(jsonBytes, bytesconv.StringToBytes("[")) && bytes.HasSuffix(jsonBytes,
+		bytesconv.StringToBytes("]")) {
+		if _, err = w.Write(bytesconv.StringToBytes(r.Prefix)); err != nil {
+			return err
+		}
+	}
 	_, err = w.Write(jsonBytes)
 	return err
 }
 
+// WriteContentType (SecureJSON) writes JSON ContentType.
+func (r SecureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// Render (PureJSON) writes the given interface object without escaping the HTML characters.
+func (r PureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	encoder := json.Codec(r.Codec).NewEncoder(w)
+	if e, ok := encoder.(interface{ SetEscapeHTML(bool) }); ok {
+		e.SetEscapeHTML(false)
+	}
+	return encoder.Encode(r.Data)
+}
+
+// WriteContentType (PureJSON) writes JSON ContentType.
+func (r PureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// Render (AsciiJSON) marshals the given interface object and writes it with the
+// non-ASCII characters escaped, with custom ContentType.
+func (r AsciiJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	ret, err := json.Codec(r.Codec).Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Grow(len(ret))
+	for _, char := range bytesconv.BytesToString(ret) {
+		switch {
+		case char < 0x80:
+			buffer.WriteByte(byte(char))
+		case char > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(char)
+			fmt.Fprintf(&buffer, "\\u%04x\\u%04x", r1, r2)
+		default:
+			fmt.Fprintf(&buffer, "\\u%04x", char)
+		}
+	}
+
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteContentType (AsciiJSON) writes JSON ContentType.
+func (r AsciiJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonASCIIContentType)
+}
+
 // Render (JsonpJSON) marshals the given interface object and writes it and its callback with custom ContentType.
 func (r JsonpJSON) Render(w http.ResponseWriter) (err error) {
 	r.WriteContentType(w)
-	ret, err := json.Marshal(r.Data)
+	ret, err := json.Codec(r.Codec).Marshal(r.Data)
 	if err != nil {
 		return err
 	}
@@ -87,7 +222,8 @@ func (r JsonpJSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, jsonpContentType)
 }
 
-// MapJSON common map json struct.
+// MapJSON common map json struct, marshaled with encoding/json by Render.
+// Context.Render marshals it with the codec of the engine.
 type MapJSON map[string]interface{}
 
 // Render (MapJSON) writes data with json ContentType.
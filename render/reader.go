@@ -0,0 +1,42 @@
+// Copyright 2018 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Reader contains the IO reader and its length, and custom ContentType and other headers.
+type Reader struct {
+	ContentType string
+	// ContentLength is the length of the data, or -1 if it is unknown.
+	ContentLength int64
+	Reader        io.Reader
+	Headers       map[string]string
+}
+
+// Render (Reader) writes data with custom ContentType and headers.
+func (r Reader) Render(w http.ResponseWriter) (err error) {
+	r.WriteContentType(w)
+	_, err = io.Copy(w, r.Reader)
+	return
+}
+
+// WriteContentType (Reader) writes custom ContentType, the Content-Length
+// if it is known and the other headers.
+func (r Reader) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, []string{r.ContentType})
+	header := w.Header()
+	if r.ContentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	}
+	for k, v := range r.Headers {
+		if header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+}
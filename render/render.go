@@ -16,9 +16,16 @@ type Render interface {
 
 var (
 	_ Render = (*JSON)(nil)
+	_ Render = (*IndentedJSON)(nil)
+	_ Render = (*SecureJSON)(nil)
+	_ Render = (*PureJSON)(nil)
+	_ Render = (*AsciiJSON)(nil)
 	_ Render = (*MapJSON)(nil)
 	_ Render = (*JsonpJSON)(nil)
 
+	_ Render     = (*XML)(nil)
+	_ Render     = (*CSV)(nil)
+	_ Render     = (*Reader)(nil)
 	_ Render     = (*Redirect)(nil)
 	_ Render     = (*Data)(nil)
 	_ Render     = (*HTML)(nil)
@@ -0,0 +1,115 @@
+package plum
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestResponseWriter(w http.ResponseWriter) *responseWriter {
+	rw := &responseWriter{}
+	rw.reset(w)
+	return rw
+}
+
+func TestResponseWriterLazyWriteHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newTestResponseWriter(rec)
+
+	if w.Written() || w.Status() != http.StatusOK || w.Size() != noWritten {
+		t.Fatalf("new writer: written=%v status=%d size=%d", w.Written(), w.Status(), w.Size())
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	if w.Written() || rec.Code != http.StatusOK {
+		t.Fatalf("WriteHeader wrote the header")
+	}
+	if w.Status() != http.StatusAccepted {
+		t.Errorf("Status() = %d, want %d", w.Status(), http.StatusAccepted)
+	}
+
+	_, _ = w.Write([]byte("abc"))
+	_, _ = w.WriteString("de")
+	if !w.Written() || w.Size() != 5 {
+		t.Errorf("written=%v size=%d, want true 5", w.Written(), w.Size())
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("recorded status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	w.WriteHeader(http.StatusTeapot)
+	if w.Status() != http.StatusAccepted {
+		t.Errorf("Status() after the body = %d, want %d", w.Status(), http.StatusAccepted)
+	}
+}
+
+func TestResponseWriterWriteHeaderNow(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newTestResponseWriter(rec)
+	w.WriteHeader(http.StatusNoContent)
+	w.WriteHeaderNow()
+	if !w.Written() || w.Size() != 0 || rec.Code != http.StatusNoContent {
+		t.Errorf("written=%v size=%d code=%d, want true 0 %d", w.Written(), w.Size(), rec.Code, http.StatusNoContent)
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newTestResponseWriter(rec)
+	w.Flush()
+	if !rec.Flushed || !w.Written() {
+		t.Errorf("flushed=%v written=%v, want true true", rec.Flushed, w.Written())
+	}
+}
+
+// controlledWriter supports hijacking and write deadlines, which http.ResponseController
+// must reach through the Unwrap method of the responseWriter.
+type controlledWriter struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	deadline time.Time
+}
+
+func (w *controlledWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func (w *controlledWriter) SetWriteDeadline(t time.Time) error {
+	w.deadline = t
+	return nil
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	cw := &controlledWriter{ResponseRecorder: httptest.NewRecorder()}
+	w := newTestResponseWriter(cw)
+	if _, _, err := w.Hijack(); err != nil {
+		t.Fatalf("Hijack() error = %v", err)
+	}
+	if !cw.hijacked || !w.Written() {
+		t.Errorf("hijacked=%v written=%v, want true true", cw.hijacked, w.Written())
+	}
+}
+
+func TestResponseWriterResponseController(t *testing.T) {
+	cw := &controlledWriter{ResponseRecorder: httptest.NewRecorder()}
+	p := newTestEngine()
+	deadline := time.Now().Add(time.Minute)
+	p.GET("/", func(c *Context) {
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+			t.Errorf("SetWriteDeadline() error = %v", err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	p.ServeHTTP(cw, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !cw.deadline.Equal(deadline) {
+		t.Errorf("deadline = %v, want %v", cw.deadline, deadline)
+	}
+	if cw.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", cw.Code, http.StatusNoContent)
+	}
+}
@@ -1,61 +1,227 @@
 package plum
 
 import (
-	"fmt"
 	"net/http"
+	"path"
+	"reflect"
+	"runtime"
 	"slices"
 	"strings"
 )
 
+// RouteInfo represents a registered route, as returned by Plum.Routes.
+type RouteInfo struct {
+	Method string
+	// Pattern is the full ServeMux pattern, such as "GET /users/{id}".
+	Pattern string
+	// Scope is the scope of the group the route was registered on.
+	Scope string
+	// Handler is the name of the handler function, the last one of the route.
+	Handler string
+	// Middlewares is the number of middlewares wrapping the handler, including the route ones.
+	Middlewares int
+}
+
+// Router registers routes under a scope. The handler chain of a route is made of the
+// middlewares of the root router, then those of each nested group down to the router
+// of the route, each in Use order, then the handler. Middlewares added with Use after
+// routes or groups are created apply to them too.
 type Router struct {
-	scope       string
-	basePath    string
-	engine      *Plum
-	middlewares []Middleware
+	scope    string
+	basePath string
+	engine   *Plum
+	parent   *Router
+	// middlewares are the middlewares added to this router, not including the parent ones.
+	middlewares []HandlerFunc
 }
 
+// Group creates a router for the routes under relativePath, whose middlewares
+// run after the ones of r.
 func (r *Router) Group(relativePath string, m ...Middleware) *Router {
 	newScope := r.scope + relativePath
 	newRouter := &Router{
 		scope:       newScope,
 		basePath:    joinPaths(r.basePath, relativePath),
 		engine:      r.engine,
-		middlewares: r.middlewares,
-	}
-	if len(m) > 0 {
-		slices.Reverse(m)
-		newRouter.middlewares = slices.Concat(m, newRouter.middlewares)
+		parent:      r,
+		middlewares: middlewareHandlers(m),
 	}
 	return newRouter
 }
+
+// Use adds middlewares to the router, they run in the given order after
+// the middlewares already added to the router and its parents.
 func (r *Router) Use(m ...Middleware) {
-	slices.Reverse(m)
-	r.middlewares = slices.Concat(m, r.middlewares)
+	r.UseFunc(middlewareHandlers(m)...)
+}
+
+// UseFunc adds gin-style middlewares to the router, which call Context.Next
+// to run the pending handlers and Context.Abort to skip them.
+func (r *Router) UseFunc(handlers ...HandlerFunc) {
+	r.middlewares = slices.Concat(r.middlewares, handlers)
+	if r.engine != nil {
+		r.engine.rebuildRoutes(r)
+	}
+}
+
+// Middlewares returns the middlewares run by the routes of the router, in order.
+func (r *Router) Middlewares() []HandlerFunc {
+	var chain []HandlerFunc
+	for router := r; router != nil; router = router.parent {
+		chain = slices.Concat(router.middlewares, chain)
+	}
+	return chain
 }
 
-func (r *Router) withMiddlewares(handler HandlerFunc) HandlerFunc {
-	for _, middleware := range r.middlewares {
-		handler = middleware(handler)
+// contains reports whether router is r or one of its nested groups.
+func (r *Router) contains(router *Router) bool {
+	for ; router != nil; router = router.parent {
+		if router == r {
+			return true
+		}
 	}
-	return handler
+	return false
+}
+
+// combineHandlers returns the handler chain of a route registered on the router:
+// its middlewares followed by the handlers of the route.
+func (r *Router) combineHandlers(handlers []HandlerFunc) []HandlerFunc {
+	middlewares := r.Middlewares()
+	if len(middlewares)+len(handlers) >= int(abortIndex) {
+		panic("plum: too many handlers")
+	}
+	return slices.Concat(middlewares, handlers)
+}
+
+func (r *Router) POST(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPost, route, handlers...)
+}
+
+// GET also serves HEAD requests, unless a HEAD route is registered for the same path.
+func (r *Router) GET(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodGet, route, handlers...)
+}
+
+func (r *Router) PUT(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPut, route, handlers...)
+}
+
+func (r *Router) PATCH(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPatch, route, handlers...)
+}
+
+func (r *Router) DELETE(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodDelete, route, handlers...)
 }
 
-func (r *Router) POST(route string, handler HandlerFunc) {
-	r.Handle(http.MethodPost, route, handler)
+func (r *Router) OPTIONS(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodOptions, route, handlers...)
 }
 
-func (r *Router) GET(route string, handler HandlerFunc) {
-	r.Handle(http.MethodGet, route, handler)
+// HEAD takes precedence over the implicit HEAD handling of a GET route.
+func (r *Router) HEAD(route string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodHead, route, handlers...)
 }
 
-func (r *Router) Handle(method, route string, handler HandlerFunc) {
+// Any registers a route that matches all the HTTP methods:
+// GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS, TRACE.
+func (r *Router) Any(route string, handlers ...HandlerFunc) {
+	r.Match(anyMethods, route, handlers...)
+}
+
+// Match registers a route that matches the specified methods.
+func (r *Router) Match(methods []string, route string, handlers ...HandlerFunc) {
+	for _, method := range methods {
+		r.Handle(method, route, handlers...)
+	}
+}
+
+// Handle registers a route for the method. The handlers run after the middlewares of
+// the router, in the given order: the ones before the last handler are the middlewares
+// of the route, which call Context.Next to run the pending handlers and Context.Abort
+// to skip them. It panics if there is no handler.
+func (r *Router) Handle(method, route string, handlers ...HandlerFunc) {
+	if len(handlers) == 0 {
+		panic("plum: no handler for " + method + " " + route)
+	}
 	if strings.HasSuffix(route, "/") {
 		route += "{$}"
 	}
+	pattern := method + " " + r.scope + route
 	rh := &RouterHandler{
-		engine: r.engine,
-		h:      r.withMiddlewares(handler),
+		engine:    r.engine,
+		handlers:  r.combineHandlers(handlers),
+		wildcards: parseWildcards(pattern),
+		fullPath:  r.scope + route,
+		router:    r,
+		route:     slices.Clone(handlers),
 	}
-	fmt.Println(method + " " + r.scope + route)
-	r.engine.mux.Handle(method+" "+r.scope+route, rh)
+	r.engine.mux.Handle(pattern, rh)
+
+	ri := RouteInfo{
+		Method:      method,
+		Pattern:     pattern,
+		Scope:       r.scope,
+		Handler:     nameOfHandler(handlers[len(handlers)-1]),
+		Middlewares: len(rh.handlers) - 1,
+	}
+	r.engine.routes = append(r.engine.routes, ri)
+	r.engine.handlers = append(r.engine.handlers, rh)
+	if r.engine.opts.printRoutes {
+		r.engine.opts.Log.Debug("plum: route registered", "method", ri.Method, "pattern", ri.Pattern,
+			"handler", ri.Handler, "middlewares", ri.Middlewares)
+	}
+}
+
+// StaticFile registers a route serving a single file of the local file system.
+func (r *Router) StaticFile(relativePath, filepath string) {
+	r.staticFileHandler(relativePath, func(c *Context) {
+		c.File(filepath)
+	})
+}
+
+// StaticFileFS registers a route serving a single file of fs, see FS to serve an embed.FS.
+func (r *Router) StaticFileFS(relativePath, filepath string, fs http.FileSystem) {
+	r.staticFileHandler(relativePath, func(c *Context) {
+		if !serveFile(c, filepath, fs) {
+			serveError(c, NewHTTPError(http.StatusNotFound, http.StatusNotFound, default404Body))
+		}
+	})
+}
+
+func (r *Router) staticFileHandler(relativePath string, handler HandlerFunc) {
+	if strings.ContainsAny(relativePath, "{}") {
+		panic("plum: URL parameters can not be used when serving a static file")
+	}
+	r.GET(relativePath, handler)
+}
+
+// Static serves the files under the root directory of the local file system,
+// without listing the directories, see Dir.
+//
+//	router.Static("/static", "/var/www")
+func (r *Router) Static(relativePath, root string) {
+	r.StaticFS(relativePath, Dir(root, false))
+}
+
+// StaticFS serves the files of fs under relativePath, see Dir and FS to control
+// the directory listing and to serve an embed.FS. A directory is served
+// by its index.html file, if any. The GET and HEAD requests go through
+// the middlewares of the router.
+func (r *Router) StaticFS(relativePath string, fs http.FileSystem) {
+	if strings.ContainsAny(relativePath, "{}") {
+		panic("plum: URL parameters can not be used when serving a static folder")
+	}
+	r.GET(strings.TrimSuffix(relativePath, "/")+"/{filepath...}", func(c *Context) {
+		// path.Clean of a rooted path never climbs above the root.
+		file := path.Clean("/" + c.Param("filepath"))
+		if file != "/" && strings.HasSuffix(c.Param("filepath"), "/") {
+			file += "/"
+		}
+		c.FileFromFS(file, fs)
+	})
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
 }
@@ -0,0 +1,118 @@
+package plum
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// orderMiddleware adds its name to the X-Order response header.
+func orderMiddleware(name string) Middleware {
+	return func(handler HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.Header().Add("X-Order", name)
+			handler(c)
+		}
+	}
+}
+
+func order(p *Plum, target string) string {
+	return strings.Join(serve(p, http.MethodGet, target).Header().Values("X-Order"), " ")
+}
+
+func TestGroupMiddlewareOrder(t *testing.T) {
+	p := newTestEngine()
+	p.Use(orderMiddleware("root1"), orderMiddleware("root2"))
+	api := p.Group("/api", orderMiddleware("api1"))
+	api.Use(orderMiddleware("api2"))
+	v1 := api.Group("/v1", orderMiddleware("v1"))
+	v1.GET("/users", func(c *Context) {})
+	api.GET("/health", func(c *Context) {})
+	p.GET("/", func(c *Context) {})
+
+	tests := []struct {
+		target, want string
+	}{
+		{"/api/v1/users", "root1 root2 api1 api2 v1"},
+		{"/api/health", "root1 root2 api1 api2"},
+		{"/", "root1 root2"},
+		{"/nope", "root1 root2"},
+	}
+	for _, tt := range tests {
+		if got := order(p, tt.target); got != tt.want {
+			t.Errorf("%s: order %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestGroupIsolation(t *testing.T) {
+	p := newTestEngine()
+	a := p.Group("/a", orderMiddleware("a"))
+	b := p.Group("/b", orderMiddleware("b"))
+	a.GET("/x", func(c *Context) {})
+	b.GET("/x", func(c *Context) {})
+	a.Use(orderMiddleware("a2"))
+
+	if got := order(p, "/a/x"); got != "a a2" {
+		t.Errorf("/a/x: order %q, want %q", got, "a a2")
+	}
+	if got := order(p, "/b/x"); got != "b" {
+		t.Errorf("/b/x: order %q, want %q", got, "b")
+	}
+}
+
+func TestLateUse(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/early", func(c *Context) {})
+	g := p.Group("/g")
+	g.GET("/route", func(c *Context) {})
+	sub := g.Group("/sub")
+	sub.GET("/route", func(c *Context) {})
+
+	p.Use(orderMiddleware("root"))
+	g.Use(orderMiddleware("g"))
+
+	tests := []struct {
+		target, want string
+	}{
+		{"/early", "root"},
+		{"/g/route", "root g"},
+		{"/g/sub/route", "root g"},
+	}
+	for _, tt := range tests {
+		if got := order(p, tt.target); got != tt.want {
+			t.Errorf("%s: order %q, want %q", tt.target, got, tt.want)
+		}
+	}
+
+	want := map[string]int{"GET /early": 1, "GET /g/route": 2, "GET /g/sub/route": 2}
+	for _, route := range p.Routes() {
+		if route.Middlewares != want[route.Pattern] {
+			t.Errorf("%s: %d middlewares, want %d", route.Pattern, route.Middlewares, want[route.Pattern])
+		}
+	}
+}
+
+func TestRouterMiddlewares(t *testing.T) {
+	p := newTestEngine()
+	root := len(p.Middlewares())
+	p.Use(orderMiddleware("root"))
+	g := p.Group("/g", orderMiddleware("g1"))
+	sub := g.Group("/sub")
+	g.Use(orderMiddleware("g2"))
+
+	if got, want := len(p.Middlewares()), root+1; got != want {
+		t.Errorf("engine: %d middlewares, want %d", got, want)
+	}
+	if got, want := len(sub.Middlewares()), root+3; got != want {
+		t.Errorf("sub group: %d middlewares, want %d", got, want)
+	}
+
+	// The chain of sub runs the middlewares of its parents first.
+	q := newTestEngine()
+	q.UseFunc(sub.Middlewares()...)
+	q.GET("/", func(c *Context) {})
+	if got, want := order(q, "/"), "root g1 g2"; got != want {
+		t.Errorf("order %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package plum
+
+import (
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SPAOptions configures the single-page application served by Router.SPA.
+type SPAOptions struct {
+	// Index is the file served for the paths of the application, "index.html" if empty.
+	Index string
+	// ExcludePrefixes are the request path prefixes, such as "/api/", answered with
+	// a 404 instead of the index when they match no file.
+	ExcludePrefixes []string
+	// Immutable reports whether the file is a hashed asset, cached for MaxAge without
+	// revalidation. By default, the files whose name has a hash segment of at least
+	// 8 characters with a digit, such as "app.3f9a2c1b.js" or "index-BxL3a9Qz.css".
+	Immutable func(name string) bool
+	// MaxAge is the cache lifetime of the hashed assets, one year if zero.
+	MaxAge time.Duration
+}
+
+// SPA serves a single-page application, such as a frontend bundle embedded with embed.FS,
+// under relativePath. The hashed assets are cached as immutable and the index with no-cache.
+// The paths which match no file and have no extension are served the index, so that
+// the client-side router handles them. A precompressed "name.gz" sibling is served
+// instead of a file if the client accepts gzip.
+//
+// The files are served like StaticFileFS rather than through StaticFS, whose
+// http.FileServer redirects "/index.html" to "/" and the directories to a trailing
+// slash instead of serving the index with its Cache-Control header.
+func (r *Router) SPA(relativePath string, fsys fs.FS, opts SPAOptions) {
+	if strings.ContainsAny(relativePath, "{}") {
+		panic("plum: URL parameters can not be used when serving a single-page application")
+	}
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	if opts.Immutable == nil {
+		opts.Immutable = isHashedAsset
+	}
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 365 * 24 * time.Hour
+	}
+	immutable := "public, max-age=" + strconv.Itoa(int(opts.MaxAge.Seconds())) + ", immutable"
+	files := http.FS(fsys)
+
+	r.GET(strings.TrimSuffix(relativePath, "/")+"/{filepath...}", func(c *Context) {
+		name := strings.TrimPrefix(path.Clean("/"+c.Param("filepath")), "/")
+		if name == "" || name == opts.Index {
+			serveSPAIndex(c, fsys, files, opts.Index)
+			return
+		}
+
+		if stat, err := fs.Stat(fsys, name); err == nil && !stat.IsDir() {
+			if opts.Immutable(name) {
+				c.Header("Cache-Control", immutable)
+			} else {
+				c.Header("Cache-Control", "no-cache")
+			}
+			serveSPAFile(c, fsys, files, name)
+			return
+		}
+
+		for _, prefix := range opts.ExcludePrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				serveError(c, NewHTTPError(http.StatusNotFound, http.StatusNotFound, default404Body))
+				return
+			}
+		}
+		// A missing asset must not be answered with the HTML of the index.
+		if path.Ext(name) != "" {
+			serveError(c, NewHTTPError(http.StatusNotFound, http.StatusNotFound, default404Body))
+			return
+		}
+		serveSPAIndex(c, fsys, files, opts.Index)
+	})
+}
+
+func serveSPAIndex(c *Context, fsys fs.FS, files http.FileSystem, index string) {
+	c.Header("Cache-Control", "no-cache")
+	if !serveSPAFile(c, fsys, files, index) {
+		serveError(c, NewHTTPError(http.StatusNotFound, http.StatusNotFound, default404Body))
+	}
+}
+
+// serveSPAFile serves the file, or its precompressed ".gz" sibling if the client accepts gzip.
+func serveSPAFile(c *Context, fsys fs.FS, files http.FileSystem, name string) bool {
+	c.Writer.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(c.requestHeader("Accept-Encoding")) {
+		return serveFile(c, name, files)
+	}
+	if stat, err := fs.Stat(fsys, name+".gz"); err != nil || stat.IsDir() {
+		return serveFile(c, name, files)
+	}
+
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	c.Header("Content-Type", ctype)
+	c.Header("Content-Encoding", "gzip")
+	if !serveFile(c, name+".gz", files) {
+		c.Header("Content-Type", "")
+		c.Header("Content-Encoding", "")
+		return serveFile(c, name, files)
+	}
+	return true
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip,
+// explicitly or through "*".
+func acceptsGzip(acceptEncoding string) bool {
+	gzip, star := -1.0, -1.0
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		weight := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if weight, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch strings.TrimSpace(name) {
+		case "gzip":
+			gzip = weight
+		case "*":
+			star = weight
+		}
+	}
+	if gzip >= 0 {
+		return gzip > 0
+	}
+	return star > 0
+}
+
+// isHashedAsset reports whether the base name of the file has a dot or dash separated
+// segment of at least 8 letters, digits or underscores with a digit, before its extension.
+func isHashedAsset(name string) bool {
+	base := path.Base(name)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	for _, segment := range strings.FieldsFunc(base, func(r rune) bool { return r == '.' || r == '-' }) {
+		if len(segment) < 8 {
+			continue
+		}
+		digit, valid := false, true
+		for _, char := range segment {
+			switch {
+			case char >= '0' && char <= '9':
+				digit = true
+			case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char == '_':
+			default:
+				valid = false
+			}
+		}
+		if digit && valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package plum
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func spaFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":                {Data: []byte("index")},
+		"favicon.ico":               {Data: []byte("icon")},
+		"assets/app.3f9a2c1b.js":    {Data: []byte("app")},
+		"assets/app.3f9a2c1b.js.gz": {Data: []byte("gzipped app")},
+		"assets/docs":               {Mode: fs.ModeDir | 0o755},
+	}
+}
+
+func TestSPA(t *testing.T) {
+	p := newTestEngine()
+	p.GET("/api/users", func(c *Context) {
+		c.String(http.StatusOK, "users")
+	})
+	p.SPA("/", spaFS(), SPAOptions{ExcludePrefixes: []string{"/api/"}})
+
+	tests := []struct {
+		name     string
+		target   string
+		encoding string
+		status   int
+		body     string
+		cache    string
+	}{
+		{"root", "/", "", http.StatusOK, "index", "no-cache"},
+		{"index", "/index.html", "", http.StatusOK, "index", "no-cache"},
+		{"history fallback", "/users/42/edit", "", http.StatusOK, "index", "no-cache"},
+		{"directory", "/assets/docs", "", http.StatusOK, "index", "no-cache"},
+		{"hashed asset", "/assets/app.3f9a2c1b.js", "", http.StatusOK, "app", "public, max-age=31536000, immutable"},
+		{"precompressed asset", "/assets/app.3f9a2c1b.js", "br, gzip", http.StatusOK, "gzipped app", "public, max-age=31536000, immutable"},
+		{"refused gzip", "/assets/app.3f9a2c1b.js", "gzip;q=0, *", http.StatusOK, "app", "public, max-age=31536000, immutable"},
+		{"unhashed asset", "/favicon.ico", "", http.StatusOK, "icon", "no-cache"},
+		{"missing asset", "/assets/missing.js", "", http.StatusNotFound, "", ""},
+		{"excluded prefix", "/api/missing", "", http.StatusNotFound, "", ""},
+		{"route", "/api/users", "", http.StatusOK, "users", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.encoding != "" {
+				req.Header.Set("Accept-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.cache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cache)
+			}
+		})
+	}
+}
+
+func TestSPAPrecompressedHeaders(t *testing.T) {
+	p := newTestEngine()
+	p.SPA("/app", spaFS(), SPAOptions{})
+
+	req := httptest.NewRequest(http.MethodGet, "/app/assets/app.3f9a2c1b.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want the type of the uncompressed file", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+}
+
+func TestIsHashedAsset(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app.3f9a2c1b.js", true},
+		{"assets/index-BxL3a9Qz.css", true},
+		{"chunk.a1b2c3d4e5.min.js", true},
+		{"logo_2x.12345678.png", true},
+		{"index.html", false},
+		{"favicon.ico", false},
+		{"vendor.abcdefgh.js", false},
+		{"app.3f9a.js", false},
+		{"app.3f9a2c1b", false},
+		{"3f9a2c1b.3f9a2c1b/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := isHashedAsset(tt.name); got != tt.want {
+			t.Errorf("isHashedAsset(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"br, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"gzip;q=0, *", false},
+		{"gzip, *;q=0", true},
+		{"identity", false},
+		{"gzip;q=x", false},
+	}
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}